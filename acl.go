@@ -0,0 +1,82 @@
+package emitter
+
+import "path"
+
+// ACL restricts principals which can emit or subscribe to topics via
+// EmitAs and OnAs. An empty list doesn't restrict the action.
+type ACL struct {
+	CanEmit      []string
+	CanSubscribe []string
+}
+
+// SetTopicACL sets ACL for the topic, it can be pattern. All ACLs of
+// the patterns covering the topic must allow the action. Emit and On
+// are not restricted. It returns error if the pattern is invalid.
+func (e *Emitter) SetTopicACL(topic string, acl ACL) error {
+	if !Test(topic) {
+		return path.ErrBadPattern
+	}
+	e.mu.Lock()
+	e.init()
+	defer e.mu.Unlock()
+	if e.acl == nil {
+		e.acl = make(map[string]ACL)
+	}
+	e.acl[topic] = acl
+	return nil
+}
+
+// EmitAs works exactly like Emit(see above) on behalf of the principal.
+// It returns ErrAccessDenied if the principal can't emit to the topic.
+func (e *Emitter) EmitAs(principal string, topic string, args ...interface{}) (*EmitHandle, error) {
+	h, err := e.publish(func([]emission) error {
+		if !e.allowed(topic, principal, func(acl ACL) []string { return acl.CanEmit }) {
+			return ErrAccessDenied
+		}
+		return nil
+	}, emission{topic: topic, args: args})
+	if err != nil {
+		return nil, err
+	}
+	return h, nil
+}
+
+// OnAs works exactly like On(see above) on behalf of the principal. It
+// returns ErrAccessDenied if the principal can't subscribe to the topic.
+func (e *Emitter) OnAs(principal string, topic string, middlewares ...func(*Event)) (<-chan Event, error) {
+	e.mu.Lock()
+	e.init()
+	ok := e.allowed(topic, principal, func(acl ACL) []string { return acl.CanSubscribe })
+	e.mu.Unlock()
+	if !ok {
+		return nil, ErrAccessDenied
+	}
+	return e.On(topic, middlewares...), nil
+}
+
+// allowed reports whether all ACLs covering the topic allow
+// the principal, it must be called under the lock.
+func (e *Emitter) allowed(topic, principal string, principals func(ACL) []string) bool {
+	for pattern, acl := range e.acl {
+		list := principals(acl)
+		if len(list) == 0 {
+			continue
+		}
+		if matched, _ := e.match(pattern, topic); !matched {
+			if matched, _ := e.match(topic, pattern); !matched {
+				continue
+			}
+		}
+		found := false
+		for _, p := range list {
+			if p == principal {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,52 @@
+package emitter
+
+import (
+	"path"
+	"testing"
+	"time"
+)
+
+func TestTopicACL(t *testing.T) {
+	ee := New(1)
+	expect(t, ee.SetTopicACL("[", ACL{}), path.ErrBadPattern)
+	expect(t, ee.SetTopicACL("system", ACL{
+		CanEmit:      []string{"admin"},
+		CanSubscribe: []string{"admin", "monitor"},
+	}), nil)
+
+	pipe, err := ee.OnAs("monitor", "system")
+	expect(t, err, nil)
+	_, err = ee.OnAs("user", "system")
+	expect(t, err, ErrAccessDenied)
+	_, err = ee.OnAs("user", "*")
+	expect(t, err, ErrAccessDenied)
+
+	h, err := ee.EmitAs("user", "system", 1)
+	expect(t, err, ErrAccessDenied)
+	expect(t, h == nil, true)
+	expect(t, len(pipe), 0)
+
+	h, err = ee.EmitAs("admin", "system", 2)
+	expect(t, err, nil)
+	h.Wait()
+	expect(t, (<-pipe).Int(0), 2)
+
+	// other topics are not restricted
+	_, err = ee.EmitAs("user", "other")
+	expect(t, err, nil)
+}
+
+func TestEmitAsDeniedTTL(t *testing.T) {
+	ee := New(1)
+	expect(t, ee.SetTopicACL("system", ACL{CanEmit: []string{"admin"}}), nil)
+	ee.On("system")
+	expect(t, ee.UseTopicTTL("system", 50*time.Millisecond), nil)
+
+	// denied events don't extend the TTL
+	for i := 0; i < 4; i++ {
+		time.Sleep(20 * time.Millisecond)
+		_, err := ee.EmitAs("user", "system")
+		expect(t, err, ErrAccessDenied)
+	}
+	expect(t, ee.HasTopic("system"), false)
+}
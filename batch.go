@@ -0,0 +1,21 @@
+package emitter
+
+// BatchEvent is an event to emit via EmitBatch.
+type BatchEvent struct {
+	Topic string
+	Args  []interface{}
+}
+
+// EmitBatch emits the events under a single lock, so events emitted
+// from other goroutines don't interleave with the batch. Listeners
+// with FlagSync receive the events in order. The returned handle
+// works as the one returned by Emit and it is done when all the
+// events are sent.
+func (e *Emitter) EmitBatch(events ...BatchEvent) *EmitHandle {
+	emissions := make([]emission, len(events))
+	for i, event := range events {
+		emissions[i] = emission{topic: event.Topic, args: event.Args}
+	}
+	h, _ := e.publish(nil, emissions...)
+	return h
+}
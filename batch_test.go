@@ -0,0 +1,29 @@
+package emitter
+
+import "testing"
+
+func TestEmitBatch(t *testing.T) {
+	ee := New(10)
+	pipe := ee.On("test*", Sync)
+	pipe2 := ee.On("other")
+
+	ee.EmitBatch(
+		BatchEvent{Topic: "test1", Args: []interface{}{1}},
+		BatchEvent{Topic: "test2", Args: []interface{}{2}},
+		BatchEvent{Topic: "other", Args: []interface{}{3}},
+		BatchEvent{Topic: "test3", Args: []interface{}{4}},
+	).Wait()
+
+	expect(t, len(pipe), 3)
+	expect(t, len(pipe2), 1)
+	e := <-pipe
+	expect(t, e.OriginalTopic, "test1")
+	expect(t, e.Int(0), 1)
+	expect(t, (<-pipe).Int(0), 2)
+	expect(t, (<-pipe).Int(0), 4)
+	expect(t, (<-pipe2).Int(0), 3)
+	expect(t, ee.Stats(false).TotalEmits, uint64(4))
+
+	// an empty batch is done immediately
+	ee.EmitBatch().Wait()
+}
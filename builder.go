@@ -0,0 +1,110 @@
+package emitter
+
+import "path"
+
+// EmitterBuilder allows to configure an emitter in a single chain.
+// Example:
+// ee := emitter.New(0).Builder().Use("*", emitter.Sync).On("test").Build()
+type EmitterBuilder struct {
+	e *Emitter
+}
+
+// Builder returns a builder for the emitter.
+func (e *Emitter) Builder() *EmitterBuilder {
+	return &EmitterBuilder{e: e}
+}
+
+// Use registers middlewares for the pattern, see Emitter.Use.
+func (b *EmitterBuilder) Use(pattern string, middlewares ...func(*Event)) *EmitterBuilder {
+	b.e.Use(pattern, middlewares...)
+	return b
+}
+
+// On subscribes a listener to the topic, see Emitter.On. The channel
+// is available via Listeners method of the built emitter.
+func (b *EmitterBuilder) On(topic string, middlewares ...func(*Event)) *EmitterBuilder {
+	b.e.On(topic, middlewares...)
+	return b
+}
+
+// Build returns the configured emitter.
+func (b *EmitterBuilder) Build() *Emitter {
+	return b.e
+}
+
+// MiddlewareBuilder allows to register middlewares for several
+// patterns in a single chain. Example:
+//
+//	err := ee.UseBuilder().
+//		For("order.*").Add(emitter.Sync).Priority(-1).
+//		For("user.*").Add(emitter.Once).
+//		Apply()
+type MiddlewareBuilder struct {
+	e        *Emitter
+	patterns []string
+	entries  []middlewareEntry
+	err      error
+}
+
+// UseBuilder returns a middleware builder for the emitter.
+func (e *Emitter) UseBuilder() *MiddlewareBuilder {
+	return &MiddlewareBuilder{e: e}
+}
+
+// For starts the configuration of the pattern, the following
+// calls are applied to the pattern.
+func (b *MiddlewareBuilder) For(pattern string) *MiddlewareBuilder {
+	if b.err == nil && !Test(pattern) {
+		b.err = path.ErrBadPattern
+	}
+	b.patterns = append(b.patterns, pattern)
+	b.entries = append(b.entries, middlewareEntry{})
+	return b
+}
+
+// Add adds middlewares to the current pattern.
+func (b *MiddlewareBuilder) Add(middlewares ...func(*Event)) *MiddlewareBuilder {
+	if entry := b.current(); entry != nil {
+		entry.fns = append(entry.fns, middlewares...)
+	}
+	return b
+}
+
+// Priority sets the priority of the current pattern, see Emitter.UsePriority.
+func (b *MiddlewareBuilder) Priority(priority int) *MiddlewareBuilder {
+	if entry := b.current(); entry != nil {
+		entry.priority = priority
+	}
+	return b
+}
+
+// Conditional sets the condition of the current pattern,
+// see Emitter.UseConditional.
+func (b *MiddlewareBuilder) Conditional(condition func() bool) *MiddlewareBuilder {
+	if entry := b.current(); entry != nil {
+		entry.condition = condition
+	}
+	return b
+}
+
+// Apply registers the configured middlewares. Nothing is registered
+// if any pattern is invalid or a method is called before For.
+func (b *MiddlewareBuilder) Apply() error {
+	if b.err != nil {
+		return b.err
+	}
+	for i, pattern := range b.patterns {
+		b.e.use(pattern, b.entries[i])
+	}
+	return nil
+}
+
+func (b *MiddlewareBuilder) current() *middlewareEntry {
+	if len(b.entries) == 0 {
+		if b.err == nil {
+			b.err = ErrNoPattern
+		}
+		return nil
+	}
+	return &b.entries[len(b.entries)-1]
+}
@@ -0,0 +1,48 @@
+package emitter
+
+import (
+	"path"
+	"testing"
+)
+
+func TestBuilder(t *testing.T) {
+	ee := New(1).Builder().Use("*", Once).On("test").Build()
+	l := ee.Listeners("test")
+	expect(t, len(l), 1)
+
+	ee.Emit("test", 42).Wait()
+	e := <-l[0]
+	expect(t, e.Int(0), 42)
+	expect(t, e.Flags, e.Flags|FlagOnce)
+
+	// the listener is removed after first sending
+	_, ok := <-l[0]
+	expect(t, ok, false)
+}
+
+func TestMiddlewareBuilder(t *testing.T) {
+	ee := New(0)
+	enabled := false
+	err := ee.UseBuilder().
+		For("order.*").Add(Sync).Add(Skip).Priority(-1).
+		For("user.*").Add(Once).
+		For("*").Add(Void).Conditional(func() bool { return enabled }).
+		Apply()
+	expect(t, err, nil)
+
+	ee.mu.Lock()
+	expect(t, len(ee.middlewares), 3)
+	expect(t, len(ee.middlewares["order.*"].fns), 2)
+	expect(t, ee.middlewares["order.*"].priority, -1)
+	expect(t, len(ee.middlewares["user.*"].fns), 1)
+	expect(t, ee.middlewares["*"].condition(), false)
+	expect(t, len(ee.getMiddlewares("order.created")), 2)
+	enabled = true
+	expect(t, len(ee.getMiddlewares("order.created")), 3)
+	ee.mu.Unlock()
+
+	expect(t, New(0).UseBuilder().Add(Sync).For("*").Apply(), ErrNoPattern)
+	ee = New(0)
+	expect(t, ee.UseBuilder().For("*").Add(Sync).For("[").Apply(), path.ErrBadPattern)
+	expect(t, len(ee.middlewares), 0)
+}
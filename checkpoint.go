@@ -0,0 +1,131 @@
+package emitter
+
+import (
+	"encoding/gob"
+	"errors"
+	"os"
+)
+
+// checkpoint is the on-disk representation of an emitter state.
+type checkpoint struct {
+	Cap    uint
+	Topics map[string][]checkpointListener
+}
+
+type checkpointListener struct {
+	Cap    int
+	Events []Event
+}
+
+// ErrInvalidCheckpoint is returned by Restore if the
+// checkpoint has more buffered events than capacity.
+var ErrInvalidCheckpoint = errors.New("emitter: invalid checkpoint")
+
+// Checkpoint saves the state of the emitter into the file by given path.
+// The state contains topics, listeners per topic and events buffered
+// in the listener channels, these events are left in place. Middlewares
+// are functions and can not be saved, so they have to be registered again
+// after Restore.
+//
+// The buffered events are taken out of the channels and put back under
+// the emitter lock, so listeners must be paused, i.e. not read, while
+// the checkpoint is made, otherwise events can be missed or reordered.
+//
+// The state is encoded with `encoding/gob`, arguments of custom types
+// should be registered via `gob.Register`.
+func Checkpoint(e *Emitter, path string) error {
+	e.mu.Lock()
+	e.init()
+	state := checkpoint{
+		Cap:    e.Cap,
+		Topics: make(map[string][]checkpointListener, len(e.listeners)),
+	}
+	for topic, listeners := range e.listeners {
+		acc := make([]checkpointListener, len(listeners))
+		for i := range listeners {
+			acc[i] = checkpointListener{
+				Cap:    cap(listeners[i].ch),
+				Events: peek(listeners[i].ch),
+			}
+		}
+		state.Topics[topic] = acc
+	}
+	e.mu.Unlock()
+
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if err := gob.NewEncoder(f).Encode(state); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// Restore creates an emitter from the file saved by Checkpoint. Listeners
+// are not created again, the buffered events are kept in the pending queue
+// instead, so the first listener subscribed to the topic receives them
+// before any other events, see EmitOrQueue. Listeners of a topic receive
+// the same events, so only the longest buffer per topic is replayed, the
+// events buffered by the other listeners of the topic are discarded.
+func Restore(path string) (*Emitter, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var state checkpoint
+	if err := gob.NewDecoder(f).Decode(&state); err != nil {
+		return nil, err
+	}
+
+	var n int
+	events := make(map[string][]Event, len(state.Topics))
+	for topic, listeners := range state.Topics {
+		for _, l := range listeners {
+			if len(l.Events) > l.Cap {
+				return nil, ErrInvalidCheckpoint
+			}
+			if len(l.Events) > len(events[topic]) {
+				events[topic] = l.Events
+			}
+		}
+		n += len(events[topic])
+	}
+
+	e := New(state.Cap)
+	// keep room for the events queued after restoring
+	e.pending.size = DefaultPendingQueueSize + n
+	for topic := range state.Topics {
+		for _, event := range events[topic] {
+			e.pending.push(pendingEvent{
+				topic:     topic,
+				args:      event.Args,
+				meta:      event.Metadata,
+				timestamp: event.Timestamp,
+			})
+		}
+	}
+	return e, nil
+}
+
+// peek returns events buffered in the channel keeping them in the same
+// order, it must be called under the lock while the channel is not read.
+// The events are sent back without blocking, since the channel is pushed
+// under the lock only.
+func peek(ch chan Event) []Event {
+	n := len(ch)
+	acc := make([]Event, 0, n)
+	for i := 0; i < n; i++ {
+		select {
+		case event := <-ch:
+			acc = append(acc, event)
+			ch <- event
+		default:
+			return acc
+		}
+	}
+	return acc
+}
@@ -0,0 +1,69 @@
+package emitter
+
+import (
+	"encoding/gob"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestCheckpointRestore(t *testing.T) {
+	ee := New(5)
+	ee.On("test")
+	for i := 0; i < 5; i++ {
+		ee.Emit("test", i).Wait()
+	}
+
+	path := filepath.Join(t.TempDir(), "emitter.checkpoint")
+	expect(t, Checkpoint(ee, path), nil)
+	// buffered events are left in place
+	expect(t, len(ee.Listeners("test")[0]), 5)
+
+	restored, err := Restore(path)
+	expect(t, err, nil)
+	expect(t, restored.Cap, uint(5))
+	// topics appear when listeners subscribe
+	expect(t, len(restored.Topics()), 0)
+
+	pipe := restored.On("test")
+	for i := 0; i < 5; i++ {
+		e := <-pipe
+		expect(t, e.Topic, "test")
+		expect(t, e.Int(0), i)
+	}
+}
+
+func TestRestoreEmitOrQueue(t *testing.T) {
+	ee := New(2)
+	ee.On("a")
+	ee.On("a")
+	ee.Emit("a", 1).Wait()
+
+	path := filepath.Join(t.TempDir(), "emitter.checkpoint")
+	expect(t, Checkpoint(ee, path), nil)
+	restored, err := Restore(path)
+	expect(t, err, nil)
+	expect(t, restored.HasTopic("a"), false)
+
+	// the event is queued after the restored one
+	restored.EmitOrQueue("a", 2).Wait()
+	pipe := restored.On("a")
+	expect(t, (<-pipe).Int(0), 1)
+	expect(t, (<-pipe).Int(0), 2)
+	expect(t, len(pipe), 0)
+}
+
+func TestRestoreInvalid(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "emitter.checkpoint")
+	f, err := os.Create(path)
+	expect(t, err, nil)
+	expect(t, gob.NewEncoder(f).Encode(checkpoint{
+		Topics: map[string][]checkpointListener{
+			"test": {{Cap: 1, Events: []Event{{Topic: "test"}, {Topic: "test"}}}},
+		},
+	}), nil)
+	expect(t, f.Close(), nil)
+
+	_, err = Restore(path)
+	expect(t, err, ErrInvalidCheckpoint)
+}
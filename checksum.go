@@ -0,0 +1,72 @@
+package emitter
+
+import (
+	"crypto/md5"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"hash"
+)
+
+// ErrUnknownChecksum is passed to the error handler by the checksum
+// middlewares if the algorithm is not supported.
+var ErrUnknownChecksum = errors.New("emitter: unknown checksum algorithm")
+
+func newHash(algo string) (func() hash.Hash, error) {
+	switch algo {
+	case "sha256":
+		return sha256.New, nil
+	case "md5":
+		return md5.New, nil
+	}
+	return nil, ErrUnknownChecksum
+}
+
+func checksum(newHash func() hash.Hash, args []interface{}) (string, error) {
+	data, err := json.Marshal(args)
+	if err != nil {
+		return "", err
+	}
+	h := newHash()
+	h.Write(data)
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// Checksum returns a middleware which computes a checksum of JSON encoded
+// arguments and stores it in the "checksum" metadata. Supported algorithms
+// are "sha256" and "md5". FlagVoid is set if the arguments can not be
+// encoded. If the algorithm is not supported every event is voided
+// and ErrUnknownChecksum is passed to the error handler.
+func Checksum(algo string) func(*Event) {
+	h, hashErr := newHash(algo)
+	return func(e *Event) {
+		if hashErr != nil {
+			e.fail(hashErr)
+			return
+		}
+		sum, err := checksum(h, e.Args)
+		if err != nil {
+			e.Flags = e.Flags | FlagVoid
+			return
+		}
+		e.SetMeta("checksum", sum)
+	}
+}
+
+// VerifyChecksum returns a middleware which sets FlagVoid flag for an
+// event if the "checksum" metadata doesn't match the arguments. The
+// unsupported algorithm is handled the same way as by Checksum.
+func VerifyChecksum(algo string) func(*Event) {
+	h, hashErr := newHash(algo)
+	return func(e *Event) {
+		if hashErr != nil {
+			e.fail(hashErr)
+			return
+		}
+		sum, err := checksum(h, e.Args)
+		if expected, _ := e.Meta("checksum"); err != nil || expected != sum {
+			e.Flags = e.Flags | FlagVoid
+		}
+	}
+}
@@ -0,0 +1,38 @@
+package emitter
+
+import "testing"
+
+func TestChecksum(t *testing.T) {
+	for _, algo := range []string{"sha256", "md5"} {
+		ee := New(1)
+		ee.Use("*", Checksum(algo))
+		pipe := ee.On("test", VerifyChecksum(algo))
+		tampered := ee.On("test", func(e *Event) {
+			e.SetMeta("checksum", "tampered")
+		}, VerifyChecksum(algo))
+		changed := ee.On("test", func(e *Event) {
+			e.SetArgs("changed")
+		}, VerifyChecksum(algo))
+
+		ee.Emit("test", "value", 42).Wait()
+		expect(t, len(pipe), 1)
+		expect(t, len(tampered), 0)
+		expect(t, len(changed), 0)
+
+		sum, ok := (<-pipe).Meta("checksum")
+		expect(t, ok, true)
+		expect(t, sum != "", true)
+	}
+}
+
+func TestChecksumUnknown(t *testing.T) {
+	errs := make(chan error, 2)
+	ee := New(1)
+	ee.SetErrorHandler(func(topic string, err error) { errs <- err })
+	ee.Use("*", Checksum("crc"))
+	pipe := ee.On("test", VerifyChecksum("crc"))
+
+	expect(t, ee.Emit("test", "value").Wait(), ErrUnknownChecksum)
+	expect(t, <-errs, ErrUnknownChecksum)
+	expect(t, len(pipe), 0)
+}
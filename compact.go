@@ -0,0 +1,44 @@
+package emitter
+
+// Compact removes topics without listeners and middlewares which patterns
+// don't cover any existing topic, it returns the number of removed topics
+// and patterns. The internal maps are reallocated, because Go maps don't
+// shrink after deleting, so it's useful to call it periodically if topics
+// are dynamic. Note, the middlewares registered before the listeners
+// subscribe are removed as well, so register them along with the topics
+// they are for.
+func (e *Emitter) Compact() int {
+	e.mu.Lock()
+	e.init()
+	listeners := make(map[string][]listener, len(e.listeners))
+	for topic, l := range e.listeners {
+		if len(l) == 0 {
+			e.stopTTL(topic)
+			continue
+		}
+		listeners[topic] = l
+	}
+	n := len(e.listeners) - len(listeners)
+	e.listeners = listeners
+	e.reindex()
+
+	var removed []string
+	middlewares := make(map[string]middlewareEntry, len(e.middlewares))
+	for pattern, entry := range e.middlewares {
+		if covers, _ := e.matched(pattern); len(covers) == 0 {
+			removed = append(removed, pattern)
+			continue
+		}
+		middlewares[pattern] = entry
+	}
+	e.middlewares = middlewares
+	onChange := e.patternChange
+	e.mu.Unlock()
+
+	if onChange != nil {
+		for _, pattern := range removed {
+			onChange(pattern, false)
+		}
+	}
+	return n + len(removed)
+}
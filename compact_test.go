@@ -0,0 +1,54 @@
+package emitter
+
+import (
+	"strconv"
+	"testing"
+)
+
+func TestCompact(t *testing.T) {
+	ee := New(0)
+	var removed []string
+	ee.OnPatternChange(func(pattern string, added bool) {
+		if !added {
+			removed = append(removed, pattern)
+		}
+	})
+	for i := 0; i < 100; i++ {
+		topic := "topic" + strconv.Itoa(i)
+		ee.On(topic)
+		ee.Use(topic, Void)
+		ee.Off(topic)
+	}
+	ee.On("test")
+	ee.Use("test", Void)
+	ee.Use("t*", Void)
+	ee.listeners["empty"] = []listener{}
+
+	expect(t, ee.Compact(), 101)
+	expect(t, len(ee.listeners), 1)
+	expect(t, len(ee.middlewares), 2)
+	expect(t, len(removed), 100)
+	expect(t, ee.Compact(), 0)
+}
+
+func BenchmarkCompact(b *testing.B) {
+	for _, compact := range []bool{false, true} {
+		b.Run("compact="+strconv.FormatBool(compact), func(b *testing.B) {
+			ee := New(0)
+			for i := 0; i < 10000; i++ {
+				topic := "topic" + strconv.Itoa(i)
+				ee.On(topic)
+				ee.Use(topic, Void)
+				ee.Off(topic)
+			}
+			ee.On("test", Void)
+			if compact {
+				ee.Compact()
+			}
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				ee.Emit("test")
+			}
+		})
+	}
+}
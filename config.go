@@ -0,0 +1,104 @@
+package emitter
+
+import (
+	"bufio"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// configCacheTTL is how long DynamicConfigMiddleware keeps the config.
+var configCacheTTL = time.Second
+
+// configFlags maps config keys to the flags they set.
+var configFlags = map[string]Flag{
+	"once":  FlagOnce,
+	"void":  FlagVoid,
+	"skip":  FlagSkip,
+	"close": FlagClose,
+	"sync":  FlagSync,
+	"dedup": FlagDedup,
+}
+
+type eventConfig struct {
+	flags   Flag
+	timeout time.Duration
+}
+
+// DynamicConfigMiddleware returns a middleware which applies settings
+// from the config file to the event. The file is read again at most
+// once per second, so the behavior can be changed without restarting.
+// The config is a flat YAML mapping, keys are flag names with boolean
+// values and "timeout" with a duration, see SendTimeout:
+//
+//	void: false
+//	skip: true
+//	timeout: 100ms
+//
+// The previous config is kept if the file can't be read or parsed.
+func DynamicConfigMiddleware(configPath string) func(*Event) {
+	var mu sync.Mutex
+	var config eventConfig
+	var loaded time.Time
+
+	return func(e *Event) {
+		mu.Lock()
+		if time.Since(loaded) >= configCacheTTL {
+			if c, err := readEventConfig(configPath); err == nil {
+				config = c
+			}
+			loaded = time.Now()
+		}
+		c := config
+		mu.Unlock()
+
+		e.Flags = e.Flags | c.flags
+		if c.timeout > 0 {
+			SendTimeout(c.timeout)(e)
+		}
+	}
+}
+
+func readEventConfig(path string) (eventConfig, error) {
+	var c eventConfig
+	f, err := os.Open(path)
+	if err != nil {
+		return c, err
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if i := strings.Index(line, "#"); i != -1 {
+			line = line[:i]
+		}
+		key, value, ok := strings.Cut(line, ":")
+		if !ok {
+			continue
+		}
+		key = strings.TrimSpace(key)
+		value = strings.Trim(strings.TrimSpace(value), `"'`)
+
+		if key == "timeout" {
+			if c.timeout, err = time.ParseDuration(value); err != nil {
+				return c, err
+			}
+			continue
+		}
+		flag, ok := configFlags[key]
+		if !ok {
+			continue
+		}
+		on, err := strconv.ParseBool(value)
+		if err != nil {
+			return c, err
+		}
+		if on {
+			c.flags = c.flags | flag
+		}
+	}
+	return c, scanner.Err()
+}
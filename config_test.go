@@ -0,0 +1,44 @@
+package emitter
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestDynamicConfigMiddleware(t *testing.T) {
+	defer func(ttl time.Duration) { configCacheTTL = ttl }(configCacheTTL)
+	configCacheTTL = 20 * time.Millisecond
+
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	write := func(config string) {
+		if err := os.WriteFile(path, []byte(config), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	write("# voided\nvoid: true\n")
+
+	ee := New(1)
+	ee.Use("*", DynamicConfigMiddleware(path))
+	pipe := ee.On("test")
+	ee.Emit("test", 1).Wait()
+	expect(t, len(pipe), 0)
+
+	write("void: false\nskip: 'true'\ntimeout: 10ms\n")
+	ee.Emit("test", 2).Wait()
+	expect(t, len(pipe), 0)
+
+	time.Sleep(30 * time.Millisecond)
+	ee.Emit("test", 3).Wait()
+	e := <-pipe
+	expect(t, e.Int(0), 3)
+	expect(t, e.Flags, FlagSkip|FlagTimeout)
+	expect(t, e.timeout, 10*time.Millisecond)
+
+	// the previous config is kept if the file is broken
+	write("void: maybe\n")
+	time.Sleep(30 * time.Millisecond)
+	ee.Emit("test", 4).Wait()
+	expect(t, (<-pipe).Int(0), 4)
+}
@@ -0,0 +1,46 @@
+package emitter
+
+import "reflect"
+
+// lastEvent keeps the last event passed to a listener with FlagDedup.
+type lastEvent struct {
+	ok    bool
+	topic string
+	args  []interface{}
+}
+
+// duplicate reports whether the event has the same topic and
+// arguments as the last one, it must be called under the emitter lock.
+func (l *lastEvent) duplicate(e Event) bool {
+	return l.ok && l.topic == e.OriginalTopic && reflect.DeepEqual(l.args, e.Args)
+}
+
+// set remembers the event sent to the listener, it must be
+// called under the emitter lock.
+func (l *lastEvent) set(e Event) {
+	l.ok = true
+	l.topic = e.OriginalTopic
+	l.args = e.Args
+}
+
+// duplicate reports whether the listener has received the event already,
+// either the last one with FlagDedup or the one with the same ID for
+// QoSExactlyOnce. It must be called under the emitter lock.
+func (l listener) duplicate(e *Emitter, event Event) bool {
+	if (event.Flags|FlagDedup) == event.Flags && l.last.duplicate(event) {
+		return true
+	}
+	id, ok := e.eventID(event)
+	return ok && l.seen.has(id)
+}
+
+// sent remembers the event sent to the listener to detect duplicates,
+// it must be called under the emitter lock.
+func (l listener) sent(e *Emitter, event Event) {
+	if (event.Flags | FlagDedup) == event.Flags {
+		l.last.set(event)
+	}
+	if id, ok := e.eventID(event); ok {
+		l.seen.add(id)
+	}
+}
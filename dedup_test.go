@@ -0,0 +1,38 @@
+package emitter
+
+import "testing"
+
+func TestDedup(t *testing.T) {
+	ee := New(10)
+	pipe := ee.On("*", Dedup)
+	pipe2 := ee.On("sensor")
+
+	ee.Emit("sensor", 1, []int{1}).Wait()
+	ee.Emit("sensor", 1, []int{1}).Wait()
+	ee.Emit("sensor", 2, []int{1}).Wait()
+	ee.Emit("sensor", 2, []int{1}).Wait()
+	// the topic is changed
+	ee.Emit("other", 2, []int{1}).Wait()
+	ee.Emit("sensor", 2, []int{1}).Wait()
+
+	expect(t, len(pipe), 4)
+	expect(t, len(pipe2), 5)
+	expect(t, (<-pipe).Int(0), 1)
+	expect(t, (<-pipe).Int(0), 2)
+	expect(t, (<-pipe).OriginalTopic, "other")
+	expect(t, (<-pipe).OriginalTopic, "sensor")
+}
+
+func TestDedupDropped(t *testing.T) {
+	ee := New(1)
+	pipe := ee.On("test", Dedup, Skip)
+
+	ee.Emit("test", 1).Wait()
+	// the channel is full, the event is dropped
+	ee.Emit("test", 2).Wait()
+	expect(t, (<-pipe).Int(0), 1)
+
+	// the dropped event is not a duplicate
+	ee.Emit("test", 2).Wait()
+	expect(t, (<-pipe).Int(0), 2)
+}
@@ -0,0 +1,68 @@
+package emitter
+
+// DeliveryReport is the result of sending an event to a listener.
+type DeliveryReport struct {
+	// Listener is the channel the event was sent to.
+	Listener <-chan Event
+	// Delivered is true if the event was sent to the channel.
+	Delivered bool
+	// Skipped is true if the event was voided or deduplicated
+	// by middlewares and sending was not attempted.
+	Skipped bool
+	// Closed is true if the listener was closed, either before
+	// the sending or because of FlagClose.
+	Closed bool
+	// Err is ErrChannelFull or ErrListenerClosed if the event
+	// was not delivered.
+	Err error
+}
+
+// EmitDetailed works exactly like Emit(see above) but returns a channel
+// which receives a report per matched listener. The channel is closed
+// when all the reports are sent, it's closed without reports if the
+// event is not emitted, e.g. the distributed lock fails.
+func (e *Emitter) EmitDetailed(topic string, args ...interface{}) <-chan DeliveryReport {
+	var reports chan DeliveryReport
+	e.publish(func(emissions []emission) error {
+		var n int
+		for _, t := range emissions[0].match {
+			n += len(e.listeners[t])
+		}
+		reports = make(chan DeliveryReport, n)
+		emissions[0].reports = reports
+		return nil
+	}, emission{topic: topic, args: args})
+	if reports == nil {
+		reports = make(chan DeliveryReport)
+		close(reports)
+	}
+	return reports
+}
+
+// skip sends the report of the skipped event if reports is not nil.
+func skip(reports chan DeliveryReport, lstnr listener) {
+	if reports != nil {
+		reports <- DeliveryReport{Listener: lstnr.ch, Skipped: true}
+	}
+}
+
+// notify sends the report of the sending if reports is not nil.
+func notify(reports chan DeliveryReport, lstnr listener, sent, remove bool, err error) {
+	if reports != nil {
+		reports <- DeliveryReport{
+			Listener:  lstnr.ch,
+			Delivered: sent,
+			Closed:    err == ErrListenerClosed || (remove && !sent),
+			Err:       err,
+		}
+	}
+}
+
+// closeReports closes the report channels of the emissions.
+func closeReports(emissions []emission) {
+	for _, em := range emissions {
+		if em.reports != nil {
+			close(em.reports)
+		}
+	}
+}
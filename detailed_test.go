@@ -0,0 +1,66 @@
+package emitter
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestEmitDetailed(t *testing.T) {
+	ee := New(1)
+	pipe := ee.On("test", Skip)
+	voided := ee.On("test", Void)
+	closed := ee.On("test", Close)
+
+	reports := map[<-chan Event]DeliveryReport{}
+	for r := range ee.EmitDetailed("test", 1) {
+		reports[r.Listener] = r
+	}
+	expect(t, len(reports), 3)
+	expect(t, reports[pipe].Delivered, true)
+	expect(t, reports[pipe].Err, nil)
+	expect(t, reports[voided].Skipped, true)
+	expect(t, reports[voided].Delivered, false)
+	expect(t, reports[closed].Delivered, true)
+
+	// the channels are full now
+	reports = map[<-chan Event]DeliveryReport{}
+	for r := range ee.EmitDetailed("test", 2) {
+		reports[r.Listener] = r
+	}
+	expect(t, len(reports), 3)
+	expect(t, reports[pipe].Delivered, false)
+	expect(t, reports[pipe].Closed, false)
+	expect(t, reports[pipe].Err, ErrChannelFull)
+	expect(t, reports[closed].Closed, true)
+	expect(t, reports[closed].Err, ErrChannelFull)
+
+	// there are no matched listeners
+	_, ok := <-ee.EmitDetailed("other")
+	expect(t, ok, false)
+}
+
+func TestEmitDetailedSync(t *testing.T) {
+	ee := New(0)
+	ee.Use("*", Sync, Skip)
+	ee.On("test")
+	reports := ee.EmitDetailed("test")
+	// reports of sync listeners are sent inline
+	expect(t, len(reports), 1)
+	r := <-reports
+	expect(t, r.Err, ErrChannelFull)
+	_, ok := <-reports
+	expect(t, ok, false)
+}
+
+func TestEmitDetailedLockFailed(t *testing.T) {
+	locker := &mockLocker{calls: make(chan string, 10), err: errors.New("locked")}
+	ee := New(1, WithDistributedLock(locker, []string{"*"}))
+	ee.On("test")
+
+	var n int
+	for range ee.EmitDetailed("test") {
+		n++
+	}
+	expect(t, n, 0)
+	expect(t, <-locker.calls, "lock test")
+}
@@ -8,8 +8,14 @@ The design goals are:
 package emitter
 
 import (
+	"context"
+	"errors"
 	"path"
+	"reflect"
+	"sort"
 	"sync"
+	"sync/atomic"
+	"time"
 )
 
 // Flag used to describe what behavior
@@ -31,6 +37,34 @@ const (
 	FlagClose
 	// FlagSync indicates to send an event synchronously.
 	FlagSync
+	// FlagTimeout indicates to skip sending if channel is blocked
+	// longer than the timeout, see SendTimeout.
+	FlagTimeout
+	// FlagDedup indicates to skip sending if the arguments are equal to
+	// the arguments of the previous event sent to the listener.
+	FlagDedup
+)
+
+var (
+	// ErrChannelFull is passed to the dead letter handler when an
+	// event was not sent because the listener channel is blocked.
+	ErrChannelFull = errors.New("emitter: listener channel is full")
+	// ErrListenerClosed is passed to the dead letter handler when an
+	// event was not sent because the listener channel is closed.
+	ErrListenerClosed = errors.New("emitter: listener channel is closed")
+	// ErrTopicNotFound is returned if the topic doesn't exist.
+	ErrTopicNotFound = errors.New("emitter: topic not found")
+	// ErrMiddlewareNotFound is returned by RemoveMiddleware if the
+	// middleware is not registered for the pattern.
+	ErrMiddlewareNotFound = errors.New("emitter: middleware not found")
+	// ErrNoPattern is returned by MiddlewareBuilder.Apply if
+	// middlewares are configured before the pattern.
+	ErrNoPattern = errors.New("emitter: pattern is not specified")
+	// ErrMacroNotFound is returned if the macro is not registered.
+	ErrMacroNotFound = errors.New("emitter: macro not found")
+	// ErrAccessDenied is returned if the principal is not allowed
+	// to emit or subscribe by ACL of the topic.
+	ErrAccessDenied = errors.New("emitter: access denied")
 )
 
 // Middlewares.
@@ -53,79 +87,353 @@ func Close(e *Event) { e.Flags = e.Flags | FlagClose }
 // Sync middleware sets FlagSync flag for an event
 func Sync(e *Event) { e.Flags = e.Flags | FlagSync }
 
+// Dedup middleware sets FlagDedup flag for an event
+func Dedup(e *Event) { e.Flags = e.Flags | FlagDedup }
+
+// SendTimeout returns a middleware which sets FlagTimeout flag for an event
+// with the given timeout
+func SendTimeout(d time.Duration) func(*Event) {
+	return func(e *Event) {
+		e.Flags = e.Flags | FlagTimeout
+		e.timeout = d
+	}
+}
+
+// WithCap returns an option for On which sets the capacity of the
+// listener channel instead of the emitter one. It is not a middleware
+// and does nothing if it's applied to an event.
+func WithCap(n uint) func(*Event) {
+	return func(e *Event) {
+		if len(e.Args) != 1 {
+			return
+		}
+		if c, ok := e.Args[0].(*listenerCap); ok {
+			*c = listenerCap(n)
+		}
+	}
+}
+
+// listenerCap is the argument of the probe event
+// which WithCap option writes the capacity into.
+type listenerCap uint
+
+// withCap is the code pointer shared by all WithCap options.
+var withCap = reflect.ValueOf(WithCap(0)).Pointer()
+
 // New returns just created Emitter struct. Capacity argument
 // will be used to create channels with given capacity by default. The
-// OnWithCap method can be used to get different capacities per listener.
-func New(capacity uint) *Emitter {
-	return &Emitter{
+// WithCap option can be used to get different capacities per listener.
+// Options are applied to the emitter in the given order.
+func New(capacity uint, options ...Option) *Emitter {
+	e := &Emitter{
 		Cap:         capacity,
 		listeners:   make(map[string][]listener),
-		middlewares: make(map[string][]func(*Event)),
+		middlewares: make(map[string]middlewareEntry),
 		isInit:      true,
+		created:     time.Now(),
+	}
+	for _, option := range options {
+		option(e)
 	}
+	return e
+}
+
+// Clone returns a new emitter with the same capacity, matcher and
+// middlewares but without listeners. Middlewares registered on the
+// clone don't affect the emitter and vice versa.
+func (e *Emitter) Clone() *Emitter {
+	e.mu.Lock()
+	e.init()
+	defer e.mu.Unlock()
+
+	clone := New(e.Cap)
+	clone.matcher = e.matcher
+	if e.index != nil {
+		clone.index = newTopicIndex()
+	}
+	clone.seq = e.seq
+	for pattern, entry := range e.middlewares {
+		entry.fns = append([]func(*Event){}, entry.fns...)
+		clone.middlewares[pattern] = entry
+	}
+	return clone
 }
 
 // Emitter is a struct that allows to emit, receive
 // event, close receiver channel, get info
 // about topics and listeners
 type Emitter struct {
-	Cap         uint
-	mu          sync.Mutex
-	listeners   map[string][]listener
-	isInit      bool
-	middlewares map[string][]func(*Event)
+	Cap           uint
+	mu            sync.Mutex
+	listeners     map[string][]listener
+	isInit        bool
+	created       time.Time
+	middlewares   map[string]middlewareEntry
+	seq           uint64
+	patternChange func(string, bool)
+	deadLetter    func(Event, error)
+	errorHandler  func(string, error)
+	qos           map[string]QoSLevel
+	matcher       Matcher
+	pending       pendingQueue
+	ttl           map[string]*topicTTL
+	idleTTL       map[string]time.Duration
+	index         *topicIndex
+	hooks         map[string][]*subscriberHook
+	macros        map[string]macro
+	acl           map[string]ACL
+	locker        DistributedLocker
+	lockTopics    []string
+	eventTypes    map[string][]reflect.Type
+	// metaQueue keeps meta events to emit in order, see queueMeta
+	metaQueue   []metaChange
+	metaRunning bool
+
+	emitted   atomic.Uint64
+	delivered atomic.Uint64
+	dropped   atomic.Uint64
 }
 
+// newListener creates a listener with the capacity, WithCap options
+// are extracted from middlewares and override the capacity.
 func newListener(capacity uint, middlewares ...func(*Event)) listener {
+	acc := middlewares[:0:0]
+	for _, fn := range middlewares {
+		if reflect.ValueOf(fn).Pointer() != withCap {
+			acc = append(acc, fn)
+			continue
+		}
+		c := listenerCap(capacity)
+		fn(&Event{Args: []interface{}{&c}})
+		capacity = uint(c)
+	}
 	return listener{
 		ch:          make(chan Event, capacity),
-		middlewares: middlewares,
+		middlewares: acc,
+		last:        &lastEvent{},
+		seen:        &seenIDs{},
 	}
 }
 
 type listener struct {
 	ch          chan Event
 	middlewares []func(*Event)
+	overflow    OverflowStrategy
+	last        *lastEvent
+	seen        *seenIDs
+	tag         string
 }
 
 func (e *Emitter) init() {
 	if !e.isInit {
 		e.listeners = make(map[string][]listener)
-		e.middlewares = make(map[string][]func(*Event))
+		e.middlewares = make(map[string]middlewareEntry)
 		e.isInit = true
+		e.created = time.Now()
 	}
 }
 
+// middlewareEntry is a set of middlewares registered for a pattern.
+type middlewareEntry struct {
+	fns       []func(*Event)
+	priority  int
+	condition func() bool
+	seq       uint64
+}
+
 // Use registers middlewares for the pattern.
 func (e *Emitter) Use(pattern string, middlewares ...func(*Event)) {
+	e.use(pattern, middlewareEntry{fns: middlewares})
+}
+
+// UseContextMiddleware registers middlewares for the pattern for the
+// lifetime of the context, see Use. The middlewares are removed when
+// the context is done unless they are replaced already, the returned
+// channel is closed then. It returns error if the pattern is invalid.
+func (e *Emitter) UseContextMiddleware(ctx context.Context, pattern string, middlewares ...func(*Event)) (<-chan struct{}, error) {
+	if !Test(pattern) {
+		return nil, path.ErrBadPattern
+	}
+	seq := e.use(pattern, middlewareEntry{fns: middlewares})
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		<-ctx.Done()
+		e.unuse(pattern, seq)
+	}()
+	return done, nil
+}
+
+// UsePriority registers middlewares for the pattern with the priority.
+// Middlewares of the matched patterns are applied in ascending order
+// of priority, and in order of registration for the same priority.
+// Use registers middlewares with zero priority.
+func (e *Emitter) UsePriority(priority int, pattern string, middlewares ...func(*Event)) error {
+	if !Test(pattern) {
+		return path.ErrBadPattern
+	}
+	e.use(pattern, middlewareEntry{fns: middlewares, priority: priority})
+	return nil
+}
+
+// UseConditional registers middlewares for the pattern which are
+// applied only if the condition returns true at the time of emitting.
+func (e *Emitter) UseConditional(pattern string, condition func() bool, middlewares ...func(*Event)) error {
+	if !Test(pattern) {
+		return path.ErrBadPattern
+	}
+	e.use(pattern, middlewareEntry{fns: middlewares, condition: condition})
+	return nil
+}
+
+// RemoveMiddleware removes the middleware registered for the pattern
+// keeping the rest ones. Functions are compared by their code pointers,
+// so closures created by the same function are not distinguished.
+func (e *Emitter) RemoveMiddleware(pattern string, fn func(*Event)) error {
 	e.mu.Lock()
 	e.init()
+
+	entry, ok := e.middlewares[pattern]
+	if !ok {
+		e.mu.Unlock()
+		return ErrMiddlewareNotFound
+	}
+	ptr := reflect.ValueOf(fn).Pointer()
+	fns := make([]func(*Event), 0, len(entry.fns))
+	for _, v := range entry.fns {
+		if reflect.ValueOf(v).Pointer() != ptr {
+			fns = append(fns, v)
+		}
+	}
+	if len(fns) == len(entry.fns) {
+		e.mu.Unlock()
+		return ErrMiddlewareNotFound
+	}
+	if len(fns) != 0 {
+		entry.fns = fns
+		e.middlewares[pattern] = entry
+		e.mu.Unlock()
+		return nil
+	}
+	delete(e.middlewares, pattern)
+	onChange := e.patternChange
+	e.mu.Unlock()
+
+	if onChange != nil {
+		onChange(pattern, false)
+	}
+	return nil
+}
+
+// OnPatternChange sets the function which is called when a pattern
+// gets its first middlewares (added is true) or loses all of them
+// (added is false). Nil removes the function.
+func (e *Emitter) OnPatternChange(fn func(pattern string, added bool)) {
+	e.mu.Lock()
 	defer e.mu.Unlock()
+	e.patternChange = fn
+}
 
-	e.middlewares[pattern] = middlewares
-	if len(e.middlewares[pattern]) == 0 {
+// use registers the middlewares entry for the pattern
+// and returns its sequence number.
+func (e *Emitter) use(pattern string, entry middlewareEntry) uint64 {
+	e.mu.Lock()
+	e.init()
+	_, existed := e.middlewares[pattern]
+	if len(entry.fns) == 0 {
 		delete(e.middlewares, pattern)
+	} else {
+		e.seq++
+		entry.seq = e.seq
+		e.middlewares[pattern] = entry
 	}
+	onChange := e.patternChange
+	e.mu.Unlock()
+
+	if added := len(entry.fns) != 0; onChange != nil && added != existed {
+		onChange(pattern, added)
+	}
+	return entry.seq
+}
+
+// unuse removes the middlewares of the pattern if they are registered
+// with the sequence number, i.e. they are not replaced since then.
+func (e *Emitter) unuse(pattern string, seq uint64) {
+	e.mu.Lock()
+	e.init()
+	entry, ok := e.middlewares[pattern]
+	ok = ok && entry.seq == seq
+	if ok {
+		delete(e.middlewares, pattern)
+	}
+	onChange := e.patternChange
+	e.mu.Unlock()
+
+	if ok && onChange != nil {
+		onChange(pattern, false)
+	}
+}
+
+// SetDeadLetterHandler sets the function which is called asynchronously
+// for every event that was dropped instead of being sent, reason is
+// ErrChannelFull or ErrListenerClosed. Nil removes the handler.
+func (e *Emitter) SetDeadLetterHandler(fn func(event Event, reason error)) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.deadLetter = fn
+}
+
+// SetErrorHandler sets the function which is called asynchronously for
+// errors which are not returned by emitting methods: an invalid pattern
+// passed to Emit, and ErrChannelFull or ErrListenerClosed if an event
+// was not sent to a listener. Nil removes the handler.
+func (e *Emitter) SetErrorHandler(fn func(topic string, err error)) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.errorHandler = fn
 }
 
 // On returns a channel that will receive events. As optional second
-// argument it takes middlewares.
+// argument it takes middlewares and options like WithCap.
 func (e *Emitter) On(topic string, middlewares ...func(*Event)) <-chan Event {
-	return e.OnWithCap(topic, e.Cap, middlewares...)
+	return e.subscribe(topic, newListener(e.Cap, middlewares...))
 }
 
-// On returns a channel that will receive events with the listener capacity
-// specified. As optional second argument it takes middlewares.
+// OnWithCap returns a channel that will receive events with the listener
+// capacity specified. As optional second argument it takes middlewares.
+//
+// Deprecated: use On with WithCap option instead.
 func (e *Emitter) OnWithCap(topic string, capacity uint, middlewares ...func(*Event)) <-chan Event {
+	return e.subscribe(topic, newListener(capacity, middlewares...))
+}
+
+func (e *Emitter) subscribe(topic string, l listener) <-chan Event {
 	e.mu.Lock()
 	e.init()
-	l := newListener(capacity, middlewares...)
 	if listeners, ok := e.listeners[topic]; ok {
 		e.listeners[topic] = append(listeners, l)
 	} else {
 		e.listeners[topic] = []listener{l}
+		if e.index != nil {
+			e.index.add(topic)
+		}
+		e.touch(topic)
+	}
+	count := len(e.listeners[topic])
+	if !isMeta(topic) {
+		e.queueMeta(TopicSubscribe, topic, count)
+	}
+
+	if pending := e.pending.take(topic, e.match); len(pending) != 0 {
+		// the lock is released after the pending events are sent
+		go e.flushPending(topic, l, pending, count)
+		return l.ch
 	}
 	e.mu.Unlock()
+
+	if !isMeta(topic) {
+		e.subscribersChanged(TopicSubscribe, topic, count)
+	}
 	return l.ch
 }
 
@@ -135,20 +443,28 @@ func (e *Emitter) Once(topic string, middlewares ...func(*Event)) <-chan Event {
 }
 
 // Off unsubscribes all listeners which were covered by
-// topic, it can be pattern as well.
-func (e *Emitter) Off(topic string, channels ...<-chan Event) {
+// topic, it can be pattern as well. It returns number of
+// removed listeners and error if pattern is invalid.
+func (e *Emitter) Off(topic string, channels ...<-chan Event) (int, error) {
 	e.mu.Lock()
-	defer e.mu.Unlock()
 	e.init()
-	match, _ := e.matched(topic)
+	match, err := e.matched(topic)
+	if err != nil {
+		e.mu.Unlock()
+		return 0, err
+	}
 
+	var removed int
+	var changes []metaChange
 	for _, _topic := range match {
 		if listeners, ok := e.listeners[_topic]; ok {
+			before := removed
 
 			if len(channels) == 0 {
 				for i := len(listeners) - 1; i >= 0; i-- {
 					close(listeners[i].ch)
 					listeners = drop(listeners, i)
+					removed++
 				}
 
 			} else {
@@ -158,16 +474,56 @@ func (e *Emitter) Off(topic string, channels ...<-chan Event) {
 						if curr == listeners[i].ch {
 							close(listeners[i].ch)
 							listeners = drop(listeners, i)
+							removed++
 						}
 					}
 				}
 			}
 			e.listeners[_topic] = listeners
+			if removed != before && !isMeta(_topic) {
+				changes = append(changes, metaChange{TopicUnsubscribe, _topic, len(listeners)})
+				e.queueMeta(TopicUnsubscribe, _topic, len(listeners))
+			}
 		}
 		if len(e.listeners[_topic]) == 0 {
 			delete(e.listeners, _topic)
+			if e.index != nil {
+				e.index.remove(_topic)
+			}
+			e.stopTTL(_topic)
+		}
+	}
+	e.mu.Unlock()
+
+	for _, c := range changes {
+		e.subscribersChanged(c.meta, c.topic, c.count)
+	}
+	return removed, nil
+}
+
+// OffAll unsubscribes all listeners from all topics. Unlike Off("*")
+// it does not depend on pattern matching.
+func (e *Emitter) OffAll() error {
+	e.mu.Lock()
+	e.init()
+
+	var last []*subscriberHook
+	for topic, listeners := range e.listeners {
+		last = append(last, e.hooks[topic]...)
+		for i := range listeners {
+			close(listeners[i].ch)
 		}
+		delete(e.listeners, topic)
+		e.stopTTL(topic)
+	}
+	e.reindex()
+	e.mu.Unlock()
+
+	// there are no listeners left for meta events
+	for _, h := range last {
+		h.onLast()
 	}
+	return nil
 }
 
 // Listeners returns slice of listeners which were covered by
@@ -189,89 +545,352 @@ func (e *Emitter) Listeners(topic string) []<-chan Event {
 	return acc
 }
 
-// Topics returns all existing topics.
-func (e *Emitter) Topics() []string {
+// ListenerCount returns number of listeners which were covered by
+// topic(it can be pattern) and error if pattern is invalid.
+func (e *Emitter) ListenerCount(topic string) (int, error) {
 	e.mu.Lock()
 	e.init()
 	defer e.mu.Unlock()
-	acc := make([]string, len(e.listeners))
-	i := 0
-	for k := range e.listeners {
-		acc[i] = k
-		i++
+
+	// the topics are matched inline, so counting doesn't allocate
+	var count int
+	for k, listeners := range e.listeners {
+		if matched, err := e.match(topic, k); err != nil {
+			return 0, err
+		} else if matched {
+			count += len(listeners)
+		} else if matched, _ := e.match(k, topic); matched {
+			count += len(listeners)
+		}
+	}
+	return count, nil
+}
+
+// TopicFilter reports whether the topic with the number
+// of listeners should be returned by Topics.
+type TopicFilter func(topic string, listeners int) bool
+
+// SubscriberCountFilter returns a filter which keeps topics
+// with at least min listeners.
+func SubscriberCountFilter(min int) TopicFilter {
+	return func(_ string, listeners int) bool {
+		return listeners >= min
+	}
+}
+
+// Topics returns all existing topics which pass the filters.
+func (e *Emitter) Topics(filters ...TopicFilter) []string {
+	e.mu.Lock()
+	e.init()
+	defer e.mu.Unlock()
+	acc := make([]string, 0, len(e.listeners))
+Topics:
+	for k, l := range e.listeners {
+		for _, filter := range filters {
+			if !filter(k, len(l)) {
+				continue Topics
+			}
+		}
+		acc = append(acc, k)
 	}
 	return acc
 }
 
-// Emit emits an event with the rest arguments to all
-// listeners which were covered by topic(it can be pattern).
-func (e *Emitter) Emit(topic string, args ...interface{}) chan struct{} {
+// TopicSubscriptionMap returns the number of listeners per topic,
+// the snapshot is taken at once.
+func (e *Emitter) TopicSubscriptionMap() map[string]int {
 	e.mu.Lock()
 	e.init()
-	done := make(chan struct{}, 1)
+	defer e.mu.Unlock()
+	acc := make(map[string]int, len(e.listeners))
+	for topic, listeners := range e.listeners {
+		acc[topic] = len(listeners)
+	}
+	return acc
+}
 
-	match, _ := e.matched(topic)
+// TopicsMatching returns existing topics which were covered by
+// pattern and error if pattern is invalid.
+func (e *Emitter) TopicsMatching(pattern string) ([]string, error) {
+	e.mu.Lock()
+	e.init()
+	defer e.mu.Unlock()
+	return e.matched(pattern)
+}
 
-	var wg sync.WaitGroup
-	var haveToWait bool
-	for _, _topic := range match {
-		listeners := e.listeners[_topic]
-		event := Event{
-			Topic:         _topic,
-			OriginalTopic: topic,
-			Args:          args,
+// HasTopic returns true if the topic exists. Unlike TopicsMatching
+// the topic is not treated as a pattern.
+func (e *Emitter) HasTopic(topic string) bool {
+	e.mu.Lock()
+	e.init()
+	defer e.mu.Unlock()
+	_, ok := e.listeners[topic]
+	return ok
+}
+
+// MapTopics renames all topics via fn, listeners of topics which get
+// the same name are merged. Nothing is renamed and an error is returned
+// if any new name is an invalid pattern. TTL of renamed topics is
+// canceled.
+func (e *Emitter) MapTopics(fn func(topic string) string) error {
+	e.mu.Lock()
+	e.init()
+	defer e.mu.Unlock()
+
+	names := make(map[string]string, len(e.listeners))
+	for topic := range e.listeners {
+		name := fn(topic)
+		if !Test(name) {
+			return path.ErrBadPattern
+		}
+		names[topic] = name
+	}
+
+	listeners := make(map[string][]listener, len(e.listeners))
+	for topic, list := range e.listeners {
+		name := names[topic]
+		if name != topic {
+			e.stopTTL(topic)
+		}
+		listeners[name] = append(listeners[name], list...)
+	}
+	e.listeners = listeners
+	e.reindex()
+	return nil
+}
+
+// Emit emits an event with the rest arguments to all
+// listeners which were covered by topic(it can be pattern).
+// The returned handle allows to wait for the emitting or
+// to cancel it, see EmitHandle.
+func (e *Emitter) Emit(topic string, args ...interface{}) *EmitHandle {
+	h, _ := e.publish(nil, emission{topic: topic, args: args})
+	return h
+}
+
+// publish matches the topics of the emissions and emits them, all
+// the emitting methods go through it. The distributed locks of the
+// matched topics are held until the emitting is finished, see
+// WithDistributedLock. The check is called under the lock with the
+// matched emissions, nothing is emitted if it returns an error and
+// the returned handle is done immediately.
+func (e *Emitter) publish(check func([]emission) error, emissions ...emission) (*EmitHandle, error) {
+	var locked []string
+	errs := make([]error, len(emissions))
+	for {
+		e.mu.Lock()
+		e.init()
+		for i := range emissions {
+			emissions[i].match, errs[i] = e.matched(emissions[i].topic)
+		}
+		required := e.lockRequired(emissions)
+		if contains(locked, required) {
+			break
+		}
+		// the listeners could change while locking,
+		// so the matching is repeated with the locks held
+		e.mu.Unlock()
+		e.release(locked)
+		locked = nil
+		if err := e.acquire(required); err != nil {
+			return finishedHandle(err), nil
+		}
+		locked = required
+	}
+
+	h := newEmitHandle()
+	for i, em := range emissions {
+		if errs[i] != nil {
+			e.fail(em.topic, errs[i])
+			h.fail(errs[i])
+		}
+	}
+	if check != nil {
+		if err := check(emissions); err != nil {
+			e.mu.Unlock()
+			closeReports(emissions)
+			if len(locked) != 0 {
+				e.release(locked)
+			}
+			h.fail(err)
+			h.close()
+			return h, err
+		}
+	}
+	// idle timers are reset by emitted events only
+	for _, em := range emissions {
+		for _, t := range em.match {
+			e.touch(t)
+		}
+	}
+	e.emitted.Add(uint64(len(emissions)))
+	e.emit(h, emissions...)
+	if len(locked) != 0 {
+		go func() {
+			h.Wait()
+			e.release(locked)
+		}()
+	}
+	return h, nil
+}
+
+// queueMeta queues the meta event for the listeners of the meta topic,
+// it must be called under the lock. Meta events are emitted one by one
+// in the order they are queued, each one after the previous is sent.
+func (e *Emitter) queueMeta(meta string, topic string, count int) {
+	if _, ok := e.listeners[meta]; !ok {
+		return
+	}
+	e.metaQueue = append(e.metaQueue, metaChange{meta, topic, count})
+	if !e.metaRunning {
+		e.metaRunning = true
+		go e.emitMeta()
+	}
+}
+
+// emitMeta emits the queued meta events until the queue is empty.
+func (e *Emitter) emitMeta() {
+	for {
+		e.mu.Lock()
+		if len(e.metaQueue) == 0 {
+			e.metaQueue = nil
+			e.metaRunning = false
+			e.mu.Unlock()
+			return
 		}
+		c := e.metaQueue[0]
+		e.metaQueue = e.metaQueue[1:]
+		if _, ok := e.listeners[c.meta]; !ok {
+			e.mu.Unlock()
+			continue
+		}
+		e.emit(newEmitHandle(), emission{
+			topic: c.meta,
+			match: []string{c.meta},
+			args:  []interface{}{c.topic, c.count},
+		}).Wait()
+	}
+}
 
-		applyMiddlewares(&event, e.getMiddlewares(_topic))
+// emission is an event to emit with the matched topics.
+type emission struct {
+	topic string
+	match []string
+	args  []interface{}
+	meta  map[string]interface{}
+	flags Flag
+	// time is the timestamp of the event, the time
+	// of emitting is used if it's zero
+	time time.Time
+	// reports receives the delivery report per listener if not nil
+	reports chan DeliveryReport
+}
 
-		// whole topic is skipping
-		// if (event.Flags | FlagVoid) == event.Flags {
-		// 	continue
-		// }
+// emit sends events to listeners of the matched topics, the handle
+// is closed when it's done and it keeps the errors of the sending. It
+// must be called with locked mutex and it unlocks the mutex.
+func (e *Emitter) emit(h *EmitHandle, emissions ...emission) *EmitHandle {
+	done := h.done
 
-	Loop:
-		for i := len(listeners) - 1; i >= 0; i-- {
-			lstnr := listeners[i]
-			evn := *(&event) // copy the event
-			applyMiddlewares(&evn, lstnr.middlewares)
+	var wg sync.WaitGroup
+	var haveToWait bool
+	now := time.Now()
+	for _, em := range emissions {
+		var id string
+		timestamp := em.time
+		if timestamp.IsZero() {
+			timestamp = now
+		}
+		for _, _topic := range em.match {
+			listeners := e.listeners[_topic]
+			event := Event{
+				Topic:         _topic,
+				OriginalTopic: em.topic,
+				Args:          em.args,
+				Flags:         em.flags,
+				Metadata:      em.meta,
+				Timestamp:     timestamp,
+				types:         e.eventTypes[em.topic],
+			}
+			if _, ok := event.Meta("id"); !ok && e.qos[_topic] == QoSExactlyOnce {
+				if id == "" {
+					id = newEventID()
+				}
+				event.SetMeta("id", id)
+			}
 
-			if (evn.Flags | FlagVoid) == evn.Flags {
-				continue Loop
+			applyMiddlewares(&event, e.getMiddlewares(_topic))
+			if event.err != nil {
+				e.fail(_topic, event.err)
+				h.fail(event.err)
 			}
 
-			if (evn.Flags | FlagSync) == evn.Flags {
-				_, remove, _ := pushEvent(done, lstnr.ch, &evn)
-				if remove {
-					defer e.Off(event.Topic, lstnr.ch)
+			// whole topic is skipping
+			// if (event.Flags | FlagVoid) == event.Flags {
+			// 	continue
+			// }
+
+		Loop:
+			for i := len(listeners) - 1; i >= 0; i-- {
+				lstnr := listeners[i]
+				evn := *(&event) // copy the event
+				applyMiddlewares(&evn, lstnr.middlewares)
+				if evn.err != nil && evn.err != event.err {
+					e.fail(_topic, evn.err)
+					h.fail(evn.err)
 				}
-			} else {
-				wg.Add(1)
-				haveToWait = true
-				go func(lstnr listener, event *Event) {
-					e.mu.Lock()
-					_, remove, _ := pushEvent(done, lstnr.ch, event)
+
+				if (evn.Flags | FlagVoid) == evn.Flags {
+					skip(em.reports, lstnr)
+					continue Loop
+				}
+
+				// delayed events are sent in background, see LeakyBucket
+				if (evn.Flags|FlagSync) == evn.Flags && evn.delay == 0 {
+					remove, retry := e.deliver(h, lstnr, &evn, &wg, em.reports)
+					haveToWait = haveToWait || retry
 					if remove {
 						defer e.Off(event.Topic, lstnr.ch)
 					}
-					wg.Done()
-					e.mu.Unlock()
-				}(lstnr, &evn)
+				} else {
+					wg.Add(1)
+					haveToWait = true
+					go func(lstnr listener, event *Event, reports chan DeliveryReport) {
+						if event.delay > 0 {
+							timer := time.NewTimer(event.delay)
+							select {
+							case <-timer.C:
+							case <-done:
+								timer.Stop()
+							}
+						}
+						e.mu.Lock()
+						remove, _ := e.deliver(h, lstnr, event, &wg, reports)
+						if remove {
+							defer e.Off(event.Topic, lstnr.ch)
+						}
+						wg.Done()
+						e.mu.Unlock()
+					}(lstnr, &evn, em.reports)
+				}
 			}
 		}
-
 	}
 	if haveToWait {
-		go func(done chan struct{}) {
+		go func() {
+			// the channel can be closed by the caller of EmitChan
 			defer func() { recover() }()
 			wg.Wait()
-			close(done)
-		}(done)
+			closeReports(emissions)
+			h.close()
+		}()
 	} else {
-		close(done)
+		closeReports(emissions)
+		h.close()
 	}
 
 	e.mu.Unlock()
-	return done
+	return h
 }
 
 func pushEvent(
@@ -283,12 +902,19 @@ func pushEvent(
 	isOnce := (event.Flags | FlagOnce) == event.Flags
 	isSkip := (event.Flags | FlagSkip) == event.Flags
 	isClose := (event.Flags | FlagClose) == event.Flags
+	isTimeout := (event.Flags | FlagTimeout) == event.Flags
+
+	var timeout time.Duration
+	if isTimeout {
+		timeout = event.timeout
+	}
 
-	sent, canceled := send(
+	sent, canceled, err := send(
 		done,
 		lstnr,
 		*event,
 		!(isSkip || isClose),
+		timeout,
 	)
 	success = sent
 
@@ -302,34 +928,98 @@ func pushEvent(
 	return
 }
 
+// deliver pushes the event to the listener. If the sending failed and
+// QoS of the topic requires it, the event is sent again in background
+// and retry is true.
+func (e *Emitter) deliver(
+	h *EmitHandle,
+	lstnr listener,
+	event *Event,
+	wg *sync.WaitGroup,
+	reports chan DeliveryReport,
+) (remove, retry bool) {
+	if lstnr.duplicate(e, *event) {
+		skip(reports, lstnr)
+		return false, false
+	}
+	sent, remove, err := lstnr.push(e, h.done, event)
+	if sent {
+		lstnr.sent(e, *event)
+	}
+	if err == ErrChannelFull && !remove && e.qos[event.Topic] != QoSAtMostOnce {
+		wg.Add(1)
+		go e.retry(h, lstnr, *event, wg, reports)
+		return false, true
+	}
+	h.fail(err)
+	e.report(*event, sent, err)
+	notify(reports, lstnr, sent, remove, err)
+	return remove, false
+}
+
+// report updates the counters and passes the event to
+// the dead letter handler if the event was not sent.
+func (e *Emitter) report(event Event, sent bool, err error) {
+	if sent {
+		e.delivered.Add(1)
+	}
+	if err != nil {
+		e.dropped.Add(1)
+		if e.deadLetter != nil {
+			go e.deadLetter(event, err)
+		}
+		e.fail(event.Topic, err)
+	}
+}
+
 func (e *Emitter) getMiddlewares(topic string) []func(*Event) {
-	var acc []func(*Event)
+	var entries []middlewareEntry
 	for pattern, v := range e.middlewares {
-		if match, _ := path.Match(pattern, topic); match {
-			acc = append(acc, v...)
-		} else if match, _ := path.Match(topic, pattern); match {
-			acc = append(acc, v...)
+		if v.condition != nil && !v.condition() {
+			continue
+		}
+		if match, _ := e.match(pattern, topic); match {
+			entries = append(entries, v)
+		} else if match, _ := e.match(topic, pattern); match {
+			entries = append(entries, v)
 		}
 	}
+	sort.Slice(entries, func(i, j int) bool {
+		if entries[i].priority != entries[j].priority {
+			return entries[i].priority < entries[j].priority
+		}
+		return entries[i].seq < entries[j].seq
+	})
+
+	var acc []func(*Event)
+	for _, v := range entries {
+		acc = append(acc, v.fns...)
+	}
 	return acc
 }
 
 func applyMiddlewares(e *Event, fns []func(*Event)) {
 	for i := range fns {
+		if e.propagationStopped {
+			return
+		}
 		fns[i](e)
 	}
 }
 
 func (e *Emitter) matched(topic string) ([]string, error) {
+	if e.index != nil && e.matcher == nil && isIndexable(topic) {
+		return e.matchedIndexed(topic)
+	}
 	acc := []string{}
 	var err error
 	for k := range e.listeners {
-		if matched, err := path.Match(topic, k); err != nil {
+		if matched, err := e.match(topic, k); err != nil {
 			return []string{}, err
 		} else if matched {
 			acc = append(acc, k)
 		} else {
-			if matched, _ := path.Match(k, topic); matched {
+			if matched, _ := e.match(k, topic); matched {
 				acc = append(acc, k)
 			}
 		}
@@ -337,6 +1027,14 @@ func (e *Emitter) matched(topic string) ([]string, error) {
 	return acc, err
 }
 
+// fail passes the error to the error handler if any,
+// it must be called under the lock.
+func (e *Emitter) fail(topic string, err error) {
+	if e.errorHandler != nil {
+		go e.errorHandler(topic, err)
+	}
+}
+
 func drop(l []listener, i int) []listener {
 	return append(l[:i], l[i+1:]...)
 }
@@ -345,15 +1043,26 @@ func send(
 	done chan struct{},
 	ch chan Event,
 	e Event, wait bool,
-) (sent, canceled bool) {
+	timeout time.Duration,
+) (sent, canceled bool, err error) {
 
 	defer func() {
 		if r := recover(); r != nil {
 			canceled = false
 			sent = false
+			err = ErrListenerClosed
 		}
 	}()
 
+	// the emitting can be canceled already, in this case
+	// the event must not be sent even if the channel is ready
+	select {
+	case <-done:
+		canceled = true
+		return
+	default:
+	}
+
 	if !wait {
 		select {
 		case <-done:
@@ -362,6 +1071,19 @@ func send(
 			sent = true
 			return
 		default:
+			err = ErrChannelFull
+			return
+		}
+
+	} else if timeout > 0 {
+		select {
+		case <-done:
+			break
+		case ch <- e:
+			sent = true
+			return
+		case <-time.After(timeout):
+			err = ErrChannelFull
 			return
 		}
 
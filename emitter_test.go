@@ -1,7 +1,12 @@
 package emitter
 
 import (
+	"context"
+	"path"
 	"reflect"
+	"sort"
+	"strconv"
+	"strings"
 	"testing"
 	"time"
 )
@@ -25,7 +30,7 @@ func TestFlatClose(t *testing.T) {
 		expect(t, len(event.Args), 1)
 		ch <- struct{}{}
 	}()
-	<-ee.Emit("test", "close")
+	ee.Emit("test", "close").Wait()
 	<-ch
 
 	go func() {
@@ -52,7 +57,7 @@ func TestBufferedBasic(t *testing.T) {
 		expect(t, len(event.Args), 2)
 		ch <- struct{}{}
 	}()
-	<-ee.Emit("test", nil, true)
+	ee.Emit("test", nil, true).Wait()
 	<-ch
 }
 
@@ -70,6 +75,45 @@ func TestOff(t *testing.T) {
 	expect(t, len(ee.Topics()), 0)
 }
 
+func TestOffCount(t *testing.T) {
+	ee := New(0)
+	pipe := ee.On("test1")
+	ee.On("test1")
+	ee.On("test2")
+
+	removed, err := ee.Off("test1", pipe)
+	expect(t, err, nil)
+	expect(t, removed, 1)
+	removed, err = ee.Off("test*")
+	expect(t, err, nil)
+	expect(t, removed, 2)
+	removed, err = ee.Off("test*")
+	expect(t, err, nil)
+	expect(t, removed, 0)
+
+	ee.On("test")
+	removed, err = ee.Off("\\")
+	expect(t, removed, 0)
+	expect(t, err, path.ErrBadPattern)
+}
+
+func TestOffAll(t *testing.T) {
+	ee := New(0)
+	for i := 0; i < 5; i++ {
+		ee.On("test" + strconv.Itoa(i))
+	}
+	l := ee.Listeners("*")
+	expect(t, len(l), 5)
+	expect(t, len(ee.Topics()), 5)
+
+	expect(t, ee.OffAll(), nil)
+	expect(t, len(ee.Topics()), 0)
+	for _, ch := range l {
+		_, ok := <-ch
+		expect(t, ok, false)
+	}
+}
+
 func TestRange(t *testing.T) {
 	ee := New(0)
 	c := 42
@@ -83,7 +127,7 @@ func TestRange(t *testing.T) {
 	}
 	l := ee.Listeners("test")
 	expect(t, len(l), 1)
-	<-ee.Emit("test", "range", "it", 42)
+	ee.Emit("test", "range", "it", 42).Wait()
 	l = ee.Listeners("test")
 	expect(t, len(l), 0)
 }
@@ -95,7 +139,7 @@ func TestCloseOnBlock(t *testing.T) {
 	l := ee.Listeners("test0")
 	expect(t, len(l), 1)
 	expect(t, len(ee.Topics()), 1)
-	<-ee.Emit("test0")
+	ee.Emit("test0").Wait()
 	l = ee.Listeners("test0")
 	expect(t, len(l), 0)
 	expect(t, len(ee.Topics()), 0)
@@ -105,24 +149,24 @@ func TestCloseOnBlock(t *testing.T) {
 	ee.On("test1")
 	ee.On("test2")
 
-	<-ee.Emit("test1")
-	<-ee.Emit("test1")
-	<-ee.Emit("test1")
+	ee.Emit("test1").Wait()
+	ee.Emit("test1").Wait()
+	ee.Emit("test1").Wait()
 	l = ee.Listeners("test1")
 	expect(t, len(l), 1)
 	expect(t, len(ee.Topics()), 2)
-	<-ee.Emit("test1") // should raise blockedError
+	ee.Emit("test1").Wait() // should raise blockedError
 	// ^^^^ and remove the topic as well
 	l = ee.Listeners("test1")
 	expect(t, len(l), 0)
 	expect(t, len(ee.Topics()), 1)
-	<-ee.Emit("test2")
-	<-ee.Emit("test2")
-	<-ee.Emit("test2")
+	ee.Emit("test2").Wait()
+	ee.Emit("test2").Wait()
+	ee.Emit("test2").Wait()
 	expect(t, len(ee.Topics()), 1)
 	l = ee.Listeners("test2")
 	expect(t, len(l[0]), 3)
-	<-ee.Emit("test2") // should raise blockedError
+	ee.Emit("test2").Wait() // should raise blockedError
 	// ^^^^ and remove the topic as well
 	l = ee.Listeners("test2")
 	expect(t, len(l), 0)
@@ -139,7 +183,7 @@ func TestCloseOnBlock(t *testing.T) {
 //
 // 	err = ee.Off("\\")
 // 	expect(t, err.Error(), "syntax error in pattern")
-// 	err = <-ee.Emit("\\")
+// 	err = ee.Emit("\\").Wait()
 // 	expect(t, err.Error(), "syntax error in pattern")
 // }
 
@@ -157,7 +201,7 @@ func TestOnOffAll(t *testing.T) {
 func TestOrSkipOnce(t *testing.T) {
 	ee := New(0)
 	pipe := ee.On("test", Skip, Once)
-	<-ee.Emit("test")
+	ee.Emit("test").Wait()
 	l := ee.Listeners("test")
 	expect(t, len(l), 1)
 	go ee.Emit("test")
@@ -189,7 +233,7 @@ func TestVoid(t *testing.T) {
 	expect(t, len(l), 0)
 	ee.On("test", Void)
 	// unblocked, sending will be skipped
-	<-ee.Emit("test")
+	ee.Emit("test").Wait()
 }
 
 func TestOnceClose(t *testing.T) {
@@ -197,7 +241,7 @@ func TestOnceClose(t *testing.T) {
 	ee.On("test", Close, Once)
 	// unblocked, the listener will be
 	// closed after first attempt
-	<-ee.Emit("test")
+	ee.Emit("test").Wait()
 }
 
 func TestCancellation(t *testing.T) {
@@ -205,7 +249,7 @@ func TestCancellation(t *testing.T) {
 	pipe := ee.On("test", Once)
 	ch := make(chan struct{})
 	go func() {
-		done := ee.Emit("test", 1)
+		done := ee.EmitChan("test", 1)
 		select {
 		case <-done:
 			expect(t, "cancellation success", "cancellation failure")
@@ -228,7 +272,7 @@ func TestCancellation(t *testing.T) {
 func TestSyncCancellation(t *testing.T) {
 	ee := New(0)
 	pipe := ee.On("test", Once, Skip)
-	close(ee.Emit("test"))
+	ee.Emit("test").Cancel()
 	select {
 	case e := <-pipe:
 		expect(t, e, nil)
@@ -264,18 +308,168 @@ func TestMiddleware(t *testing.T) {
 	pipe2 := ee.On("test")
 
 	for i := 0; i < 10; i++ {
-		<-ee.Emit("test", i)
+		ee.Emit("test", i).Wait()
 	}
 	expect(t, len(pipe), 4)
 	expect(t, len(pipe2), 10)
 }
 
+func TestStopPropagation(t *testing.T) {
+	ee := New(1)
+	var called bool
+	ee.Use("*", func(e *Event) {
+		expect(t, e.IsPropagationStopped(), false)
+		e.StopPropagation()
+		expect(t, e.IsPropagationStopped(), true)
+	})
+	pipe := ee.On("test", func(e *Event) {
+		called = true
+	})
+	ee.Emit("test").Wait()
+	expect(t, called, false)
+	expect(t, len(pipe), 1)
+}
+
+func TestUsePriority(t *testing.T) {
+	ee := New(1)
+	appendArg := func(v string) func(*Event) {
+		return func(e *Event) { e.Args = append(e.Args, v) }
+	}
+	expect(t, ee.UsePriority(2, "*", appendArg("log")), nil)
+	expect(t, ee.UsePriority(1, "test", appendArg("auth")), nil)
+	expect(t, ee.UsePriority(2, "t*", appendArg("audit")), nil)
+	ee.Use("te*", appendArg("default"))
+	expect(t, ee.UsePriority(0, "\\", appendArg("invalid")), path.ErrBadPattern)
+
+	pipe := ee.On("test")
+	for i := 0; i < 10; i++ {
+		ee.Emit("test").Wait()
+		e := <-pipe
+		expect(t, len(e.Args), 4)
+		expect(t, e.String(0), "default")
+		expect(t, e.String(1), "auth")
+		expect(t, e.String(2), "log")
+		expect(t, e.String(3), "audit")
+	}
+}
+
+func TestRemoveMiddleware(t *testing.T) {
+	ee := New(1)
+	ee.Use("*", Once, Sync)
+	expect(t, ee.RemoveMiddleware("*", Once), nil)
+	expect(t, ee.RemoveMiddleware("*", Once), ErrMiddlewareNotFound)
+	expect(t, ee.RemoveMiddleware("test", Sync), ErrMiddlewareNotFound)
+	expect(t, len(ee.middlewares["*"].fns), 1)
+
+	pipe := ee.On("test")
+	ee.Emit("test").Wait()
+	e := <-pipe
+	expect(t, e.Flags, FlagSync)
+
+	expect(t, ee.RemoveMiddleware("*", Sync), nil)
+	expect(t, len(ee.middlewares), 0)
+}
+
+func TestUseConditional(t *testing.T) {
+	ee := New(1)
+	var maintenance bool
+	expect(t, ee.UseConditional("*", func() bool { return maintenance }, Void), nil)
+	expect(t, ee.UseConditional("\\", func() bool { return true }, Void), path.ErrBadPattern)
+	pipe := ee.On("test")
+
+	ee.Emit("test").Wait()
+	expect(t, len(pipe), 1)
+	<-pipe
+
+	maintenance = true
+	ee.Emit("test").Wait()
+	expect(t, len(pipe), 0)
+
+	maintenance = false
+	ee.Emit("test").Wait()
+	expect(t, len(pipe), 1)
+}
+
+func TestOnPatternChange(t *testing.T) {
+	ee := New(0)
+	var changes []string
+	ee.OnPatternChange(func(pattern string, added bool) {
+		changes = append(changes, pattern+":"+strconv.FormatBool(added))
+	})
+
+	ee.Use("test*", Once)
+	ee.Use("test*", Once, Sync) // already added
+	expect(t, ee.UsePriority(1, "other", Sync), nil)
+	expect(t, ee.RemoveMiddleware("test*", Once), nil)
+	expect(t, ee.RemoveMiddleware("test*", Sync), nil)
+	ee.Use("other")
+	ee.Use("unknown")
+
+	expect(t, len(changes), 4)
+	expect(t, changes[0], "test*:true")
+	expect(t, changes[1], "other:true")
+	expect(t, changes[2], "test*:false")
+	expect(t, changes[3], "other:false")
+}
+
+func TestClone(t *testing.T) {
+	ee := New(1)
+	ee.Use("*", Sync)
+	pipe := ee.On("test")
+
+	clone := ee.Clone()
+	expect(t, clone.Cap, uint(1))
+	expect(t, len(clone.Topics()), 0)
+	clonePipe := clone.On("test")
+
+	clone.Emit("test").Wait()
+	expect(t, len(pipe), 0)
+	e := <-clonePipe
+	expect(t, e.Flags, FlagSync)
+
+	clone.Use("*", Void)
+	ee.Emit("test").Wait()
+	expect(t, len(pipe), 1)
+	clone.Emit("test").Wait()
+	expect(t, len(clonePipe), 0)
+}
+
+func TestMapTopics(t *testing.T) {
+	ee := New(1)
+	pipe := ee.On("user_created")
+	pipe2 := ee.On("user_deleted")
+	pipe3 := ee.On("userDeleted")
+	camelCase := func(topic string) string {
+		parts := strings.Split(topic, "_")
+		for i := 1; i < len(parts); i++ {
+			parts[i] = strings.ToUpper(parts[i][:1]) + parts[i][1:]
+		}
+		return strings.Join(parts, "")
+	}
+
+	expect(t, ee.MapTopics(func(string) string { return "\\" }), path.ErrBadPattern)
+	expect(t, ee.HasTopic("user_created"), true)
+
+	expect(t, ee.MapTopics(camelCase), nil)
+	topics := ee.Topics()
+	sort.Strings(topics)
+	expect(t, len(topics), 2)
+	expect(t, topics[0], "userCreated")
+	expect(t, topics[1], "userDeleted")
+
+	ee.Emit("userCreated").Wait()
+	ee.Emit("userDeleted").Wait()
+	expect(t, len(pipe), 1)
+	expect(t, len(pipe2), 1)
+	expect(t, len(pipe3), 1)
+}
+
 func TestSync(t *testing.T) {
 	ee := New(1)
 	ee.Use("*", Sync)
 	pipe := ee.On("test")
 	pipe2 := ee.On("test", Once)
-	_, isOpened := <-ee.Emit("test", 42)
+	_, isOpened := <-ee.Emit("test", 42).Done()
 	expect(t, len(pipe), 1)
 	expect(t, len(pipe2), 1)
 
@@ -291,7 +485,7 @@ func TestSync(t *testing.T) {
 	ee = New(0)
 	ee.Once("*", Void)
 	ee.On("test:void", Void, func(e *Event) {})
-	<-ee.Emit("test:void")
+	ee.Emit("test:void").Wait()
 }
 
 func TestCallbackOnlyUsage(t *testing.T) {
@@ -312,10 +506,224 @@ func TestCustomCap(t *testing.T) {
 	// Listen with extended capacity, use Skip to avoid hang
 	pipe := ee.OnWithCap("test", 2, Skip)
 
-	<-ee.Emit("test", 0)
-	<-ee.Emit("test", 1)
-	<-ee.Emit("test", 2) // should get dropped, proves 2 was used
+	ee.Emit("test", 0).Wait()
+	ee.Emit("test", 1).Wait()
+	ee.Emit("test", 2).Wait() // should get dropped, proves 2 was used
+	expect(t, len(pipe), 2)
+}
+
+func TestTopicsFilter(t *testing.T) {
+	ee := New(0)
+	for topic, n := range map[string]int{"one": 1, "three": 3, "five": 5} {
+		for i := 0; i < n; i++ {
+			ee.On(topic)
+		}
+	}
+	topics := ee.Topics(SubscriberCountFilter(3))
+	sort.Strings(topics)
+	expect(t, strings.Join(topics, ","), "five,three")
+	expect(t, len(ee.Topics()), 3)
+	expect(t, len(ee.Topics(SubscriberCountFilter(3), SubscriberCountFilter(4))), 1)
+}
+
+func TestTopicSubscriptionMap(t *testing.T) {
+	ee := New(0)
+	counts := map[string]int{"a": 1, "b": 2, "c": 3, "d": 4}
+	for topic, n := range counts {
+		for i := 0; i < n; i++ {
+			ee.On(topic)
+		}
+	}
+	snapshot := ee.TopicSubscriptionMap()
+	ee.Off("a")
+	ee.On("b")
+	ee.On("e")
+
+	expect(t, len(snapshot), 4)
+	for topic, n := range counts {
+		expect(t, snapshot[topic], n)
+	}
+	expect(t, len(ee.TopicSubscriptionMap()), 4)
+	expect(t, ee.TopicSubscriptionMap()["b"], 3)
+}
+
+func TestUseContextMiddleware(t *testing.T) {
+	ee := New(0)
+	ctx, cancel := context.WithCancel(context.Background())
+	var calls int
+	done, err := ee.UseContextMiddleware(ctx, "*", func(*Event) { calls++ })
+	expect(t, err, nil)
+	ee.On("test", Skip)
+
+	ee.Emit("test").Wait()
+	expect(t, calls, 1)
+	cancel()
+	<-done
+	ee.Emit("test").Wait()
+	expect(t, calls, 1)
+
+	// replaced middlewares are not removed
+	ctx, cancel = context.WithCancel(context.Background())
+	done, _ = ee.UseContextMiddleware(ctx, "*", Void)
+	ee.Use("*", Sync)
+	cancel()
+	<-done
+	expect(t, len(ee.getMiddlewares("test")), 1)
+
+	_, err = ee.UseContextMiddleware(context.Background(), "[", Void)
+	expect(t, err, path.ErrBadPattern)
+}
+
+func TestWithCap(t *testing.T) {
+	ee := New(0)
+	pipe := ee.On("test", WithCap(2), Skip)
+	once := ee.On("test", Once, WithCap(1), WithCap(3))
+	expect(t, cap(pipe), 2)
+	expect(t, cap(once), 3)
+
+	ee.mu.Lock()
+	expect(t, len(ee.listeners["test"][0].middlewares), 1)
+	expect(t, len(ee.listeners["test"][1].middlewares), 1)
+	ee.mu.Unlock()
+
+	ee.Emit("test", 0).Wait()
+	ee.Emit("test", 1).Wait()
+	ee.Emit("test", 2).Wait()
 	expect(t, len(pipe), 2)
+	expect(t, len(once), 1)
+
+	// the option does nothing as a middleware
+	e := Event{Args: []interface{}{1}}
+	WithCap(1)(&e)
+	expect(t, e.Args[0], 1)
+}
+
+func TestTopicsMatching(t *testing.T) {
+	ee := New(0)
+	ee.On("user.created")
+	ee.On("user.deleted")
+	ee.On("order.created")
+
+	topics, err := ee.TopicsMatching("user.*")
+	expect(t, err, nil)
+	sort.Strings(topics)
+	expect(t, len(topics), 2)
+	expect(t, topics[0], "user.created")
+	expect(t, topics[1], "user.deleted")
+
+	topics, err = ee.TopicsMatching("\\")
+	expect(t, len(topics), 0)
+	expect(t, err, path.ErrBadPattern)
+}
+
+func TestSendTimeout(t *testing.T) {
+	ee := New(0)
+	pipe := ee.On("test", SendTimeout(10*time.Millisecond))
+
+	start := time.Now()
+	select {
+	case <-ee.Emit("test").Done():
+	case <-time.After(time.Second):
+		t.Fatal("emit is blocked")
+	}
+	expect(t, time.Since(start) >= 10*time.Millisecond, true)
+
+	select {
+	case e := <-pipe:
+		t.Errorf("unexpected event %v", e)
+	default:
+	}
+	expect(t, len(ee.Listeners("test")), 1)
+}
+
+func TestHasTopic(t *testing.T) {
+	ee := New(0)
+	expect(t, ee.HasTopic("test"), false)
+	pipe := ee.On("test")
+	expect(t, ee.HasTopic("test"), true)
+	expect(t, ee.HasTopic("*"), false)
+	ee.Off("test", pipe)
+	expect(t, ee.HasTopic("test"), false)
+}
+
+func TestListenerCount(t *testing.T) {
+	ee := New(0)
+	ee.On("test1")
+	ee.On("test1")
+	ee.On("test2")
+	ee.On("other")
+
+	count, err := ee.ListenerCount("test*")
+	expect(t, err, nil)
+	expect(t, count, 3)
+	count, err = ee.ListenerCount("*")
+	expect(t, err, nil)
+	expect(t, count, len(ee.Listeners("*")))
+
+	count, err = ee.ListenerCount("\\")
+	expect(t, count, 0)
+	expect(t, err, path.ErrBadPattern)
+
+	allocs := testing.AllocsPerRun(100, func() {
+		ee.ListenerCount("test*")
+	})
+	expect(t, allocs, float64(0))
+}
+
+func TestDeadLetterHandler(t *testing.T) {
+	ee := New(0)
+	type dead struct {
+		event  Event
+		reason error
+	}
+	ch := make(chan dead, 1)
+	ee.SetDeadLetterHandler(func(event Event, reason error) {
+		ch <- dead{event, reason}
+	})
+
+	ee.On("test", Skip)
+	ee.Emit("test", 42).Wait()
+	d := <-ch
+	expect(t, d.reason, ErrChannelFull)
+	expect(t, d.event.Int(0), 42)
+
+	ee.Off("test")
+	ee.On("test", Sync, Skip)
+	ee.Emit("test", 37).Wait()
+	d = <-ch
+	expect(t, d.reason, ErrChannelFull)
+	expect(t, d.event.Int(0), 37)
+}
+
+func TestErrorHandler(t *testing.T) {
+	ee := New(0)
+	type failure struct {
+		topic string
+		err   error
+	}
+	ch := make(chan failure, 1)
+	ee.SetErrorHandler(func(topic string, err error) {
+		ch <- failure{topic, err}
+	})
+
+	ee.On("test", Skip)
+	ee.Emit("test").Wait()
+	f := <-ch
+	expect(t, f.topic, "test")
+	expect(t, f.err, ErrChannelFull)
+
+	ee.Emit("[").Wait()
+	f = <-ch
+	expect(t, f.topic, "[")
+	expect(t, f.err, path.ErrBadPattern)
+
+	ee.SetErrorHandler(nil)
+	ee.Emit("test").Wait()
+	select {
+	case <-ch:
+		t.Error("the handler is removed")
+	case <-time.After(10 * time.Millisecond):
+	}
 }
 
 func expect(t *testing.T, a interface{}, b interface{}) {
@@ -1,11 +1,88 @@
 package emitter
 
+import (
+	"encoding/json"
+	"errors"
+	"reflect"
+	"time"
+)
+
+// ErrArgIndex is returned by SetArg if the index is out of range.
+var ErrArgIndex = errors.New("emitter: argument index out of range")
+
 // Event is a structure to send events contains
 // some helpers to cast primitive types easily.
 type Event struct {
 	Topic, OriginalTopic string
 	Flags                Flag
 	Args                 []interface{}
+	// Metadata contains values attached by middlewares, use
+	// SetMeta to change it.
+	Metadata map[string]interface{}
+	// Timestamp is the time of emitting.
+	Timestamp time.Time
+
+	timeout            time.Duration
+	delay              time.Duration
+	propagationStopped bool
+
+	// types are the registered argument types, see RegisterEventType
+	types []reflect.Type
+	// err is the configuration error of a middleware, see fail
+	err error
+}
+
+// fail sets FlagVoid flag for the event and keeps the first error,
+// the emitter passes it to the error handler. It's used by middlewares
+// which are misconfigured.
+func (e *Event) fail(err error) {
+	e.Flags = e.Flags | FlagVoid
+	if e.err == nil {
+		e.err = err
+	}
+}
+
+// StopPropagation prevents the rest middlewares from
+// being applied to the event.
+func (e *Event) StopPropagation() { e.propagationStopped = true }
+
+// IsPropagationStopped returns true if StopPropagation
+// was called for the event.
+func (e Event) IsPropagationStopped() bool { return e.propagationStopped }
+
+// SetArg replaces the argument by index. The arguments are copied
+// before the change because they are shared between listeners.
+func (e *Event) SetArg(index int, v interface{}) error {
+	if index < 0 || index >= len(e.Args) {
+		return ErrArgIndex
+	}
+	args := make([]interface{}, len(e.Args))
+	copy(args, e.Args)
+	args[index] = v
+	e.Args = args
+	return nil
+}
+
+// SetArgs replaces all the arguments.
+func (e *Event) SetArgs(args ...interface{}) {
+	e.Args = args
+}
+
+// SetMeta sets the metadata value by key. The metadata is copied
+// before the change because it is shared between listeners.
+func (e *Event) SetMeta(key string, v interface{}) {
+	meta := make(map[string]interface{}, len(e.Metadata)+1)
+	for k, v := range e.Metadata {
+		meta[k] = v
+	}
+	meta[key] = v
+	e.Metadata = meta
+}
+
+// Meta returns the metadata value by key.
+func (e Event) Meta(key string) (interface{}, bool) {
+	v, ok := e.Metadata[key]
+	return v, ok
 }
 
 // Int returns casted into int type argument by index.
@@ -75,3 +152,49 @@ func (e Event) Bool(index uint, dflt ...bool) bool {
 	}
 	return d
 }
+
+// jsonEvent is a JSON representation of an event.
+type jsonEvent struct {
+	Topic         string        `json:"topic"`
+	OriginalTopic string        `json:"originalTopic"`
+	Flags         Flag          `json:"flags"`
+	Args          []interface{} `json:"args"`
+
+	Metadata  map[string]interface{} `json:"metadata,omitempty"`
+	Timestamp *time.Time             `json:"timestamp,omitempty"`
+}
+
+// MarshalJSON implements json.Marshaler interface.
+func (e Event) MarshalJSON() ([]byte, error) {
+	j := jsonEvent{
+		Topic:         e.Topic,
+		OriginalTopic: e.OriginalTopic,
+		Flags:         e.Flags,
+		Args:          e.Args,
+		Metadata:      e.Metadata,
+	}
+	if !e.Timestamp.IsZero() {
+		j.Timestamp = &e.Timestamp
+	}
+	return json.Marshal(j)
+}
+
+// UnmarshalJSON implements json.Unmarshaler interface. Arguments
+// are decoded into the default types of `encoding/json`, so numbers
+// become float64.
+func (e *Event) UnmarshalJSON(data []byte) error {
+	var j jsonEvent
+	if err := json.Unmarshal(data, &j); err != nil {
+		return err
+	}
+	e.Topic = j.Topic
+	e.OriginalTopic = j.OriginalTopic
+	e.Flags = j.Flags
+	e.Args = j.Args
+	e.Metadata = j.Metadata
+	e.Timestamp = time.Time{}
+	if j.Timestamp != nil {
+		e.Timestamp = *j.Timestamp
+	}
+	return nil
+}
@@ -1,6 +1,11 @@
 package emitter
 
-import "testing"
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+	"time"
+)
 
 func TestEventTypeCast(t *testing.T) {
 	ee := New(0)
@@ -16,3 +21,75 @@ func TestEventTypeCast(t *testing.T) {
 	expect(t, e.String(6, "_"), "_")
 	expect(t, e.Bool(7, true), true)
 }
+
+func TestEventJSON(t *testing.T) {
+	e := Event{
+		Topic:         "test",
+		OriginalTopic: "*",
+		Flags:         FlagOnce | FlagSync,
+		Args:          []interface{}{"value", 42.37, true, nil},
+	}
+	data, err := json.Marshal(e)
+	expect(t, err, nil)
+	expect(t, string(data), `{"topic":"test","originalTopic":"*","flags":34,"args":["value",42.37,true,null]}`)
+
+	var decoded Event
+	expect(t, json.Unmarshal(data, &decoded), nil)
+	expect(t, reflect.DeepEqual(decoded, e), true)
+	expect(t, decoded.String(0), "value")
+	expect(t, decoded.Float(1), 42.37)
+	expect(t, decoded.Bool(2), true)
+
+	e.Timestamp = time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC)
+	data, err = json.Marshal(e)
+	expect(t, err, nil)
+	expect(t, json.Unmarshal(data, &decoded), nil)
+	expect(t, decoded.Timestamp.Equal(e.Timestamp), true)
+
+	expect(t, json.Unmarshal([]byte(`{"flags":"once"}`), &decoded) != nil, true)
+}
+
+func TestEventSetArg(t *testing.T) {
+	ee := New(1)
+	pipe := ee.On("test", func(e *Event) {
+		expect(t, e.SetArg(1, "changed"), nil)
+		expect(t, e.SetArg(2, "out of range"), ErrArgIndex)
+		expect(t, e.SetArg(-1, "out of range"), ErrArgIndex)
+	})
+	pipe2 := ee.On("test", func(e *Event) {
+		e.SetArgs(37)
+	})
+	pipe3 := ee.On("test")
+
+	ee.Emit("test", 42, "value").Wait()
+	e := <-pipe
+	expect(t, e.Int(0), 42)
+	expect(t, e.String(1), "changed")
+	e = <-pipe2
+	expect(t, len(e.Args), 1)
+	expect(t, e.Int(0), 37)
+	// the other listeners are not affected
+	e = <-pipe3
+	expect(t, e.String(1), "value")
+}
+
+func TestEventMeta(t *testing.T) {
+	ee := New(1)
+	pipe := ee.On("test", func(e *Event) {
+		e.SetMeta("key", "changed")
+	})
+	pipe2 := ee.On("test")
+	ee.Use("*", func(e *Event) {
+		_, ok := e.Meta("key")
+		expect(t, ok, false)
+		e.SetMeta("key", "value")
+	})
+
+	ee.Emit("test").Wait()
+	v, ok := (<-pipe).Meta("key")
+	expect(t, ok, true)
+	expect(t, v, "changed")
+	// the other listeners are not affected
+	v, _ = (<-pipe2).Meta("key")
+	expect(t, v, "value")
+}
@@ -0,0 +1,42 @@
+package emitter
+
+import "reflect"
+
+// RegisterEventType registers the argument types of events emitted to the
+// topic, the topic is not treated as a pattern. Samples define the types
+// by index, e.g. RegisterEventType("order", 0, "") means the first
+// argument is int and the second one is string, nil samples leave the
+// types unknown. The types are available to middlewares, see NilSafe.
+// Calling it again replaces the types, no samples remove them.
+func (e *Emitter) RegisterEventType(topic string, samples ...interface{}) {
+	var types []reflect.Type
+	if len(samples) != 0 {
+		types = make([]reflect.Type, len(samples))
+		for i, sample := range samples {
+			if sample != nil {
+				types[i] = reflect.TypeOf(sample)
+			}
+		}
+	}
+
+	e.mu.Lock()
+	e.init()
+	defer e.mu.Unlock()
+	if types == nil {
+		delete(e.eventTypes, topic)
+		return
+	}
+	if e.eventTypes == nil {
+		e.eventTypes = make(map[string][]reflect.Type)
+	}
+	e.eventTypes[topic] = types
+}
+
+// argType returns the registered type of the argument by
+// index, it's nil if the type is unknown.
+func (e Event) argType(index int) reflect.Type {
+	if index < len(e.types) {
+		return e.types[index]
+	}
+	return nil
+}
@@ -13,16 +13,25 @@ type Group struct {
 
 	mu        sync.Mutex
 	listeners []listener
+	pipeline  []func(Event) Event
 	isInit    bool
 
 	stop chan struct{}
 	done chan struct{}
+	// quit stops filtering goroutines
+	quit chan struct{}
 
 	cmu   sync.Mutex
 	cases []reflect.SelectCase
 
 	lmu      sync.Mutex
 	isListen bool
+
+	// wmu guards the number of opened sources and the wait channel
+	wmu        sync.Mutex
+	sources    int
+	wait       chan struct{}
+	waitClosed bool
 }
 
 // Flush reset the group to the initial state.
@@ -33,8 +42,13 @@ func (g *Group) Flush() {
 	g.stopIfListen()
 	close(g.stop)
 	close(g.done)
+	close(g.quit)
 	g.isInit = false
 	g.init()
+	g.wmu.Lock()
+	n := g.sources
+	g.wmu.Unlock()
+	g.addSources(-n)
 }
 
 // Add adds channels which were already subscribed to
@@ -57,6 +71,83 @@ func (g *Group) Add(channels ...<-chan Event) {
 	}
 	g.cases = append(g.cases, cases...)
 	g.cmu.Unlock()
+	g.addSources(len(channels))
+}
+
+// Wait returns a channel which is closed when all the channels added
+// to the group are closed and their events are passed to the listeners.
+func (g *Group) Wait() <-chan struct{} {
+	g.wmu.Lock()
+	defer g.wmu.Unlock()
+	if g.wait == nil {
+		g.wait = make(chan struct{})
+		g.waitClosed = false
+		if g.sources == 0 {
+			close(g.wait)
+			g.waitClosed = true
+		}
+	}
+	return g.wait
+}
+
+// addSources changes the number of opened sources
+// and closes the wait channel if there are no ones.
+func (g *Group) addSources(n int) {
+	g.wmu.Lock()
+	defer g.wmu.Unlock()
+	g.sources += n
+	if g.sources > 0 && g.waitClosed {
+		g.wait = nil
+	}
+	if g.sources == 0 && g.wait != nil && !g.waitClosed {
+		close(g.wait)
+		g.waitClosed = true
+	}
+}
+
+// AddFiltered adds the channel which was already subscribed to some
+// events, only events for which the filter returns true are passed
+// to the group. Filtering stops on Flush or Off without arguments.
+func (g *Group) AddFiltered(ch <-chan Event, filter func(Event) bool) {
+	g.mu.Lock()
+	g.init()
+	quit := g.quit
+	g.mu.Unlock()
+
+	filtered := make(chan Event)
+	go func() {
+		defer close(filtered)
+		for {
+			select {
+			case e, ok := <-ch:
+				if !ok {
+					return
+				}
+				if !filter(e) {
+					continue
+				}
+				select {
+				case filtered <- e:
+				case <-quit:
+					return
+				}
+			case <-quit:
+				return
+			}
+		}
+	}()
+	g.Add(filtered)
+}
+
+// Pipeline adds processing steps which are applied in sequence to
+// every event passed to the group listeners. A step can return zero
+// Event to filter the event out, the rest steps are skipped then.
+func (g *Group) Pipeline(fns ...func(Event) Event) *Group {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.init()
+	g.pipeline = append(g.pipeline, fns...)
+	return g
 }
 
 // On returns subscribed channel.
@@ -101,6 +192,8 @@ func (g *Group) Off(channels ...<-chan Event) {
 		}
 	} else {
 		g.listeners = make([]listener, 0)
+		close(g.quit)
+		g.quit = make(chan struct{})
 	}
 }
 
@@ -138,13 +231,24 @@ func (g *Group) listen() {
 			if !isOpened && len(g.cases) > i {
 				// remove this case
 				g.cases = append(g.cases[:i], g.cases[i+1:]...)
+				g.addSources(-1)
+				continue
 			}
 
 			e := val.Interface().(Event)
+			g.mu.Lock()
+			for _, fn := range g.pipeline {
+				if e = fn(e); reflect.DeepEqual(e, Event{}) {
+					break
+				}
+			}
+			if reflect.DeepEqual(e, Event{}) {
+				g.mu.Unlock()
+				continue
+			}
 			// use unblocked mode
 			e.Flags = e.Flags | FlagSkip
 			// send events to all listeners
-			g.mu.Lock()
 			for index := range g.listeners {
 				l := g.listeners[index]
 				pushEvent(g.done, l.ch, &e)
@@ -160,6 +264,7 @@ func (g *Group) init() {
 	}
 	g.stop = make(chan struct{})
 	g.done = make(chan struct{})
+	g.quit = make(chan struct{})
 	g.cases = []reflect.SelectCase{
 		{
 			Dir:  reflect.SelectRecv,
@@ -167,5 +272,6 @@ func (g *Group) init() {
 		},
 	}
 	g.listeners = make([]listener, 0)
+	g.pipeline = nil
 	g.isInit = true
 }
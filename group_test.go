@@ -1,6 +1,12 @@
 package emitter
 
-import "testing"
+import (
+	"path"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
 
 func TestGroupInternals(t *testing.T) {
 	g := &Group{}
@@ -34,11 +40,11 @@ func TestGroupBasic(t *testing.T) {
 
 	expect(t, len(pipe), 0)
 
-	<-e.Emit("*", 1)
-	<-e.Emit("*", 2)
-	<-e2.Emit("*", 3)
-	<-e3.Emit("*", 4)
-	<-e3.Emit("*", 5)
+	e.Emit("*", 1).Wait()
+	e.Emit("*", 2).Wait()
+	e2.Emit("*", 3).Wait()
+	e3.Emit("*", 4).Wait()
+	e3.Emit("*", 5).Wait()
 
 	// departure/arrival order
 	expect(t, (<-pipe).Int(0), 1)
@@ -65,3 +71,98 @@ func TestGroupFlushOnOff(t *testing.T) {
 	g.Off()
 	expect(t, len(g.listeners), 0)
 }
+
+func TestGroupAddFiltered(t *testing.T) {
+	g := &Group{Cap: 5}
+	e := New(0)
+	e2 := New(10)
+	e.Use("*", Sync)
+	e2.Use("*", Sync)
+
+	g.Add(e.On("*"))
+	src := e2.On("*")
+	g.AddFiltered(src, func(event Event) bool {
+		matched, _ := path.Match("payment.*", event.OriginalTopic)
+		return matched
+	})
+	pipe := g.On()
+
+	e.Emit("order.created").Wait()
+	expect(t, (<-pipe).OriginalTopic, "order.created")
+	e2.Emit("order.created").Wait()
+	e2.Emit("payment.done").Wait()
+	expect(t, (<-pipe).OriginalTopic, "payment.done")
+
+	g.Flush()
+	// the filtering goroutine is stopped, nobody reads the channel
+	e2.Emit("payment.done").Wait()
+	e2.Emit("payment.done").Wait()
+	time.Sleep(10 * time.Millisecond)
+	expect(t, len(src), 2)
+}
+
+func TestGroupWait(t *testing.T) {
+	g := &Group{Cap: 10}
+	// there are no sources
+	<-g.Wait()
+
+	e := New(0)
+	e2 := New(0)
+	e.Use("*", Sync)
+	e2.Use("*", Sync)
+	g.Add(e.On("*"))
+	g.AddFiltered(e2.On("*"), func(Event) bool { return true })
+	pipe := g.On()
+	wait := g.Wait()
+
+	e.Emit("test", 1).Wait()
+	e2.Emit("test", 2).Wait()
+	e.Off("*")
+	select {
+	case <-wait:
+		t.Error("the group is not drained")
+	case <-time.After(10 * time.Millisecond):
+	}
+
+	e2.Off("*")
+	select {
+	case <-wait:
+	case <-time.After(time.Second):
+		t.Fatal("the group is not drained")
+	}
+	expect(t, len(pipe), 2)
+}
+
+func TestGroupPipeline(t *testing.T) {
+	g := &Group{Cap: 10}
+	e := New(0)
+	e.Use("*", Sync)
+	g.Add(e.On("*"))
+	pipe := g.Pipeline(
+		func(event Event) Event {
+			event.Args = []interface{}{event.Int(0) * 2}
+			return event
+		},
+		func(event Event) Event {
+			if event.Int(0) < 0 {
+				return Event{}
+			}
+			return event
+		},
+	).Pipeline(func(event Event) Event {
+		event.Args = []interface{}{strconv.Itoa(event.Int(0))}
+		return event
+	}).On()
+
+	for _, i := range []int{1, -2, 3, 0, -5} {
+		e.Emit("test", i).Wait()
+	}
+	e.Off("*")
+	<-g.Wait()
+
+	var acc []string
+	for len(pipe) != 0 {
+		acc = append(acc, (<-pipe).String(0))
+	}
+	expect(t, strings.Join(acc, ","), "2,6,0")
+}
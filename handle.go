@@ -0,0 +1,90 @@
+package emitter
+
+import "sync"
+
+// EmitHandle controls the emitting started by Emit.
+type EmitHandle struct {
+	// done is closed when the emitting is finished or canceled,
+	// listeners are not pushed after that
+	done chan struct{}
+	errc chan error
+
+	mu  sync.Mutex
+	err error
+
+	cancel sync.Once
+	finish sync.Once
+}
+
+func newEmitHandle() *EmitHandle {
+	return &EmitHandle{
+		done: make(chan struct{}, 1),
+		errc: make(chan error, 1),
+	}
+}
+
+// finishedHandle returns a handle of the emitting
+// which is finished already with the error.
+func finishedHandle(err error) *EmitHandle {
+	h := newEmitHandle()
+	h.fail(err)
+	h.close()
+	return h
+}
+
+// EmitChan works exactly like Emit(see above) but returns the done
+// channel, closing the channel cancels the emitting.
+//
+// Deprecated: use Emit and the Cancel method of the handle instead.
+func (e *Emitter) EmitChan(topic string, args ...interface{}) chan struct{} {
+	return e.Emit(topic, args...).done
+}
+
+// Done returns a channel which receives the first error of the emitting
+// if any, e.g. an invalid pattern, ErrAccessDenied, the error of the
+// distributed lock or ErrChannelFull. The channel is closed when the
+// emitting is finished.
+func (h *EmitHandle) Done() <-chan error {
+	return h.errc
+}
+
+// Cancel stops sending the event to listeners which have not received
+// it yet. It does nothing if the emitting is finished already.
+func (h *EmitHandle) Cancel() {
+	h.cancel.Do(func() { close(h.done) })
+}
+
+// Wait blocks until the emitting is finished and
+// returns the first error of the emitting if any.
+func (h *EmitHandle) Wait() error {
+	<-h.errc
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return h.err
+}
+
+// fail keeps the first error of the emitting.
+func (h *EmitHandle) fail(err error) {
+	if err == nil {
+		return
+	}
+	h.mu.Lock()
+	if h.err == nil {
+		h.err = err
+	}
+	h.mu.Unlock()
+}
+
+// close finishes the emitting, the error is sent to the Done channel.
+func (h *EmitHandle) close() {
+	h.finish.Do(func() {
+		h.mu.Lock()
+		err := h.err
+		h.mu.Unlock()
+		if err != nil {
+			h.errc <- err
+		}
+		close(h.errc)
+		h.Cancel()
+	})
+}
@@ -0,0 +1,60 @@
+package emitter
+
+import (
+	"errors"
+	"path"
+	"testing"
+	"time"
+)
+
+func TestEmitHandle(t *testing.T) {
+	ee := New(0)
+	pipe := ee.On("test")
+	ee.On("test")
+
+	h := ee.Emit("test", 1)
+	select {
+	case <-h.Done():
+		t.Fatal("nobody reads the listeners")
+	case <-time.After(10 * time.Millisecond):
+	}
+	h.Cancel()
+	h.Wait()
+	// the handle can be canceled several times
+	h.Cancel()
+
+	select {
+	case <-pipe:
+		t.Error("the emitting is canceled")
+	case <-time.After(10 * time.Millisecond):
+	}
+
+	ee.Use("*", Void)
+	h = ee.Emit("test", 2)
+	expect(t, h.Wait(), nil)
+	h.Cancel()
+}
+
+func TestEmitHandleErrors(t *testing.T) {
+	ee := New(1)
+	pipe := ee.On("test", Skip)
+
+	h := ee.Emit("test", 1)
+	expect(t, h.Wait(), nil)
+	_, ok := <-h.Done()
+	expect(t, ok, false)
+
+	// the channel is full
+	h = ee.Emit("test", 2)
+	expect(t, <-h.Done(), ErrChannelFull)
+	expect(t, h.Wait(), ErrChannelFull)
+	<-pipe
+
+	expect(t, ee.Emit("[").Wait(), path.ErrBadPattern)
+	expect(t, ee.EmitOrQueue("queued").Wait(), nil)
+
+	locker := &mockLocker{calls: make(chan string, 10), err: errors.New("locked")}
+	ee = New(1, WithDistributedLock(locker, []string{"*"}))
+	ee.On("test")
+	expect(t, ee.Emit("test").Wait(), locker.err)
+}
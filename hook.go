@@ -0,0 +1,72 @@
+package emitter
+
+import "io"
+
+type subscriberHook struct {
+	onFirst, onLast func()
+}
+
+type hookCloser struct {
+	e     *Emitter
+	topic string
+	hook  *subscriberHook
+}
+
+// SubscriberHook registers functions which are called when the first
+// listener subscribes to the topic and when the last one unsubscribes.
+// The topic is not treated as a pattern. Nil functions are ignored.
+// Close removes the hook.
+func (e *Emitter) SubscriberHook(topic string, onFirst func(), onLast func()) io.Closer {
+	noop := func() {}
+	if onFirst == nil {
+		onFirst = noop
+	}
+	if onLast == nil {
+		onLast = noop
+	}
+	h := &subscriberHook{onFirst: onFirst, onLast: onLast}
+
+	e.mu.Lock()
+	e.init()
+	defer e.mu.Unlock()
+	if e.hooks == nil {
+		e.hooks = make(map[string][]*subscriberHook)
+	}
+	e.hooks[topic] = append(e.hooks[topic], h)
+	return hookCloser{e: e, topic: topic, hook: h}
+}
+
+func (c hookCloser) Close() error {
+	c.e.mu.Lock()
+	defer c.e.mu.Unlock()
+	hooks := c.e.hooks[c.topic]
+	for i := range hooks {
+		if hooks[i] == c.hook {
+			hooks = append(hooks[:i:i], hooks[i+1:]...)
+			break
+		}
+	}
+	if len(hooks) == 0 {
+		delete(c.e.hooks, c.topic)
+	} else {
+		c.e.hooks[c.topic] = hooks
+	}
+	return nil
+}
+
+// subscribersChanged calls the subscriber hooks of the topic, it must be
+// called without the lock. The meta event is queued by the caller under
+// the lock, see queueMeta.
+func (e *Emitter) subscribersChanged(meta string, topic string, count int) {
+	e.mu.Lock()
+	hooks := e.hooks[topic]
+	e.mu.Unlock()
+
+	for _, h := range hooks {
+		if meta == TopicSubscribe && count == 1 {
+			h.onFirst()
+		} else if meta == TopicUnsubscribe && count == 0 {
+			h.onLast()
+		}
+	}
+}
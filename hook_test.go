@@ -0,0 +1,41 @@
+package emitter
+
+import "testing"
+
+func TestSubscriberHook(t *testing.T) {
+	ee := New(0)
+	var calls []string
+	h1 := ee.SubscriberHook("test", func() {
+		calls = append(calls, "first1")
+	}, func() {
+		calls = append(calls, "last1")
+	})
+	ee.SubscriberHook("test", func() {
+		calls = append(calls, "first2")
+	}, func() {
+		calls = append(calls, "last2")
+	})
+	ee.SubscriberHook("other", func() {
+		calls = append(calls, "other")
+	}, nil)
+
+	l := ee.On("test")
+	ee.On("test")
+	ee.Off("test", l)
+	expect(t, len(calls), 2)
+	expect(t, calls[0], "first1")
+	expect(t, calls[1], "first2")
+	ee.Off("test")
+	expect(t, len(calls), 4)
+	expect(t, calls[2], "last1")
+	expect(t, calls[3], "last2")
+
+	expect(t, h1.Close(), nil)
+	ee.On("test")
+	ee.On("other")
+	ee.OffAll()
+	expect(t, len(calls), 7)
+	expect(t, calls[4], "first2")
+	expect(t, calls[5], "other")
+	expect(t, calls[6], "last2")
+}
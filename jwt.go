@@ -0,0 +1,101 @@
+package emitter
+
+import (
+	"crypto"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"reflect"
+	"strings"
+	"time"
+)
+
+// ErrNoPublicKey is passed to the error handler by JWTAuth
+// if the public key is nil.
+var ErrNoPublicKey = errors.New("emitter: public key is nil")
+
+// JWTAuth returns a middleware which sets FlagVoid flag for an event if
+// the "jwt" metadata is not a JSON Web Token signed by the key with
+// RS256 algorithm, the token is expired or its claims don't contain
+// all the required claims with the same values. If the key is nil or
+// the required claims can't be encoded to JSON every event is voided
+// and the error is passed to the error handler.
+func JWTAuth(publicKey *rsa.PublicKey, requiredClaims map[string]interface{}) func(*Event) {
+	required, err := jwtClaims(requiredClaims)
+	if publicKey == nil {
+		err = ErrNoPublicKey
+	}
+
+	return func(e *Event) {
+		if err != nil {
+			e.fail(err)
+			return
+		}
+		token, _ := e.Meta("jwt")
+		s, _ := token.(string)
+		claims, ok := verifyJWT(publicKey, s)
+		if ok {
+			for k, v := range required {
+				if !reflect.DeepEqual(claims[k], v) {
+					ok = false
+					break
+				}
+			}
+		}
+		if !ok {
+			e.Flags = e.Flags | FlagVoid
+		}
+	}
+}
+
+// jwtClaims makes the values of the claims comparable
+// with the decoded claims of a token.
+func jwtClaims(claims map[string]interface{}) (map[string]interface{}, error) {
+	required := map[string]interface{}{}
+	b, err := json.Marshal(claims)
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(b, &required); err != nil {
+		return nil, err
+	}
+	return required, nil
+}
+
+// verifyJWT returns the claims of the token if it's valid.
+func verifyJWT(publicKey *rsa.PublicKey, token string) (map[string]interface{}, bool) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return nil, false
+	}
+	var header struct {
+		Alg string `json:"alg"`
+	}
+	if !decodeJWTPart(parts[0], &header) || header.Alg != "RS256" {
+		return nil, false
+	}
+	sig, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return nil, false
+	}
+	hash := sha256.Sum256([]byte(parts[0] + "." + parts[1]))
+	if rsa.VerifyPKCS1v15(publicKey, crypto.SHA256, hash[:], sig) != nil {
+		return nil, false
+	}
+
+	var claims map[string]interface{}
+	if !decodeJWTPart(parts[1], &claims) {
+		return nil, false
+	}
+	if exp, ok := claims["exp"].(float64); ok && time.Now().Unix() >= int64(exp) {
+		return nil, false
+	}
+	return claims, true
+}
+
+func decodeJWTPart(part string, v interface{}) bool {
+	b, err := base64.RawURLEncoding.DecodeString(part)
+	return err == nil && json.Unmarshal(b, v) == nil
+}
@@ -0,0 +1,91 @@
+package emitter
+
+import (
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"testing"
+	"time"
+)
+
+func signJWT(t *testing.T, key *rsa.PrivateKey, claims map[string]interface{}) string {
+	header := base64.RawURLEncoding.EncodeToString([]byte(`{"alg":"RS256","typ":"JWT"}`))
+	b, err := json.Marshal(claims)
+	if err != nil {
+		t.Fatal(err)
+	}
+	payload := header + "." + base64.RawURLEncoding.EncodeToString(b)
+	hash := sha256.Sum256([]byte(payload))
+	sig, err := rsa.SignPKCS1v15(rand.Reader, key, crypto.SHA256, hash[:])
+	if err != nil {
+		t.Fatal(err)
+	}
+	return payload + "." + base64.RawURLEncoding.EncodeToString(sig)
+}
+
+func TestJWTAuth(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	other, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	mw := JWTAuth(&key.PublicKey, map[string]interface{}{"role": "admin", "level": 2})
+	isVoid := func(token interface{}) bool {
+		e := &Event{}
+		e.SetMeta("jwt", token)
+		mw(e)
+		return (e.Flags | FlagVoid) == e.Flags
+	}
+
+	valid := signJWT(t, key, map[string]interface{}{
+		"sub":   "42",
+		"role":  "admin",
+		"level": 2,
+		"exp":   time.Now().Add(time.Hour).Unix(),
+	})
+	expect(t, isVoid(valid), false)
+
+	// tampered signature
+	tampered := valid[:len(valid)-4] + "AAAA"
+	if tampered == valid {
+		tampered = valid[:len(valid)-4] + "BBBB"
+	}
+	expect(t, isVoid(tampered), true)
+	expect(t, isVoid(signJWT(t, other, map[string]interface{}{"role": "admin", "level": 2})), true)
+
+	// missing or wrong claims
+	expect(t, isVoid(signJWT(t, key, map[string]interface{}{"role": "admin"})), true)
+	expect(t, isVoid(signJWT(t, key, map[string]interface{}{"role": "user", "level": 2})), true)
+
+	// expired token
+	expect(t, isVoid(signJWT(t, key, map[string]interface{}{
+		"role":  "admin",
+		"level": 2,
+		"exp":   time.Now().Add(-time.Hour).Unix(),
+	})), true)
+
+	expect(t, isVoid("not.a.token"), true)
+	expect(t, isVoid(nil), true)
+
+	// fail closed on bad configuration
+	for _, mw := range []func(*Event){
+		JWTAuth(nil, nil),
+		JWTAuth(&key.PublicKey, map[string]interface{}{"role": func() {}}),
+	} {
+		errs := make(chan error, 1)
+		ee := New(1)
+		ee.SetErrorHandler(func(topic string, err error) { errs <- err })
+		ee.Use("*", func(e *Event) { e.SetMeta("jwt", valid) }, mw)
+		pipe := ee.On("test")
+		err := ee.Emit("test").Wait()
+		expect(t, err != nil, true)
+		expect(t, <-errs, err)
+		expect(t, len(pipe), 0)
+	}
+}
@@ -0,0 +1,91 @@
+package emitter
+
+import "sort"
+
+// DistributedLocker is a lock shared between processes,
+// e.g. backed by a database or a coordination service.
+type DistributedLocker interface {
+	Lock(topic string) error
+	Unlock(topic string) error
+}
+
+// WithDistributedLock makes the emitting methods acquire the lock of
+// each matched topic covered by the topics, they can be patterns,
+// before emitting and release it when the emitting is finished. So the
+// events of the topics are delivered exclusively across the processes
+// sharing the locker, whatever topic or pattern is emitted. The event
+// is not emitted if a lock fails, errors are passed to the error
+// handler, see SetErrorHandler.
+func WithDistributedLock(locker DistributedLocker, topics []string) Option {
+	return func(e *Emitter) {
+		e.locker = locker
+		e.lockTopics = append([]string{}, topics...)
+	}
+}
+
+// lockRequired returns the sorted matched topics of the emissions
+// which require the distributed lock, it must be called under the lock.
+func (e *Emitter) lockRequired(emissions []emission) []string {
+	if e.locker == nil {
+		return nil
+	}
+	var topics []string
+	seen := make(map[string]struct{})
+	for _, em := range emissions {
+		for _, topic := range em.match {
+			if _, ok := seen[topic]; ok {
+				continue
+			}
+			seen[topic] = struct{}{}
+			for _, pattern := range e.lockTopics {
+				if matched, _ := e.match(pattern, topic); matched || pattern == topic {
+					topics = append(topics, topic)
+					break
+				}
+			}
+		}
+	}
+	sort.Strings(topics)
+	return topics
+}
+
+// acquire locks the topics in order, so emitters locking overlapping
+// topics don't deadlock. If a lock fails the acquired locks are released
+// and the error is returned. It must be called without the lock.
+func (e *Emitter) acquire(topics []string) error {
+	for i, topic := range topics {
+		if err := e.locker.Lock(topic); err != nil {
+			e.mu.Lock()
+			e.fail(topic, err)
+			e.mu.Unlock()
+			e.release(topics[:i])
+			return err
+		}
+	}
+	return nil
+}
+
+// release unlocks the topics, it must be called without the lock.
+func (e *Emitter) release(topics []string) {
+	for _, topic := range topics {
+		if err := e.locker.Unlock(topic); err != nil {
+			e.mu.Lock()
+			e.fail(topic, err)
+			e.mu.Unlock()
+		}
+	}
+}
+
+// contains reports whether all the sorted topics are in the sorted set.
+func contains(set, topics []string) bool {
+	i := 0
+	for _, topic := range topics {
+		for i < len(set) && set[i] < topic {
+			i++
+		}
+		if i == len(set) || set[i] != topic {
+			return false
+		}
+	}
+	return true
+}
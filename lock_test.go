@@ -0,0 +1,59 @@
+package emitter
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+type mockLocker struct {
+	calls chan string
+	err   error
+}
+
+func (l *mockLocker) Lock(topic string) error {
+	l.calls <- "lock " + topic
+	return l.err
+}
+
+func (l *mockLocker) Unlock(topic string) error {
+	l.calls <- "unlock " + topic
+	return nil
+}
+
+func TestDistributedLock(t *testing.T) {
+	locker := &mockLocker{calls: make(chan string, 10)}
+	ee := New(1, WithDistributedLock(locker, []string{"order.*"}))
+	pipe := ee.On("order.created")
+
+	ee.Emit("order.created", 1).Wait()
+	expect(t, <-locker.calls, "lock order.created")
+	expect(t, <-locker.calls, "unlock order.created")
+	expect(t, (<-pipe).Int(0), 1)
+
+	// other topics are not locked
+	ee.Emit("user.created").Wait()
+	time.Sleep(10 * time.Millisecond)
+	expect(t, len(locker.calls), 0)
+
+	// matched topics are locked whatever is emitted
+	ee.Emit("*", 2).Wait()
+	expect(t, <-locker.calls, "lock order.created")
+	expect(t, <-locker.calls, "unlock order.created")
+	expect(t, (<-pipe).Int(0), 2)
+
+	ee.EmitWithToken("token", "order.*", 3).Wait()
+	expect(t, <-locker.calls, "lock order.created")
+	expect(t, <-locker.calls, "unlock order.created")
+	expect(t, (<-pipe).Int(0), 3)
+
+	// the event is not emitted if the lock fails
+	failed := make(chan error, 1)
+	ee.SetErrorHandler(func(topic string, err error) { failed <- err })
+	locker.err = errors.New("locked")
+	ee.Emit("order.created", 2).Wait()
+	expect(t, <-locker.calls, "lock order.created")
+	expect(t, (<-failed).Error(), "locked")
+	expect(t, len(pipe), 0)
+	expect(t, len(locker.calls), 0)
+}
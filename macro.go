@@ -0,0 +1,88 @@
+package emitter
+
+import "path"
+
+// macro is a named set of middlewares registered for several patterns.
+type macro struct {
+	patterns []string
+	fns      []func(*Event)
+	// seqs keeps the sequence numbers of the registered middlewares
+	// per pattern, it's changed under the lock
+	seqs map[string]uint64
+}
+
+// UseMacro registers middlewares for all the patterns at once under
+// the name, see Use. The previous macro with the same name is removed.
+// It returns error if any pattern is invalid, nothing is registered then.
+func (e *Emitter) UseMacro(name string, patterns []string, middlewares ...func(*Event)) error {
+	for _, pattern := range patterns {
+		if !Test(pattern) {
+			return path.ErrBadPattern
+		}
+	}
+	m := macro{
+		patterns: append([]string{}, patterns...),
+		fns:      append([]func(*Event){}, middlewares...),
+		seqs:     make(map[string]uint64, len(patterns)),
+	}
+
+	e.mu.Lock()
+	e.init()
+	prev, ok := e.macros[name]
+	if e.macros == nil {
+		e.macros = make(map[string]macro)
+	}
+	e.macros[name] = m
+	e.mu.Unlock()
+
+	if ok {
+		prev.remove(e)
+	}
+	m.apply(e)
+	return nil
+}
+
+// ApplyMacro registers the middlewares of the macro for its patterns
+// again, e.g. after they were replaced by Use.
+func (e *Emitter) ApplyMacro(name string) error {
+	e.mu.Lock()
+	m, ok := e.macros[name]
+	e.mu.Unlock()
+	if !ok {
+		return ErrMacroNotFound
+	}
+	m.apply(e)
+	return nil
+}
+
+// RemoveMacro removes middlewares of all the macro patterns and the
+// macro itself. Middlewares which replaced the macro ones are kept.
+func (e *Emitter) RemoveMacro(name string) error {
+	e.mu.Lock()
+	m, ok := e.macros[name]
+	delete(e.macros, name)
+	e.mu.Unlock()
+	if !ok {
+		return ErrMacroNotFound
+	}
+	m.remove(e)
+	return nil
+}
+
+func (m macro) apply(e *Emitter) {
+	for _, pattern := range m.patterns {
+		seq := e.use(pattern, middlewareEntry{fns: m.fns})
+		e.mu.Lock()
+		m.seqs[pattern] = seq
+		e.mu.Unlock()
+	}
+}
+
+func (m macro) remove(e *Emitter) {
+	for _, pattern := range m.patterns {
+		e.mu.Lock()
+		seq := m.seqs[pattern]
+		e.mu.Unlock()
+		e.unuse(pattern, seq)
+	}
+}
@@ -0,0 +1,38 @@
+package emitter
+
+import (
+	"path"
+	"testing"
+)
+
+func TestMacro(t *testing.T) {
+	ee := New(0)
+	patterns := []string{"order.*", "user.*", "payment"}
+	expect(t, ee.UseMacro("sync", patterns, Sync, Skip), nil)
+	expect(t, len(ee.middlewares), 3)
+	for _, pattern := range patterns {
+		expect(t, len(ee.middlewares[pattern].fns), 2)
+	}
+
+	ee.Use("payment", Void)
+	expect(t, ee.ApplyMacro("sync"), nil)
+	expect(t, len(ee.middlewares["payment"].fns), 2)
+
+	// replaced middlewares are kept
+	ee.Use("user.*", Void)
+	expect(t, ee.RemoveMacro("sync"), nil)
+	expect(t, len(ee.middlewares), 1)
+	expect(t, len(ee.middlewares["user.*"].fns), 1)
+	ee.Use("user.*")
+	expect(t, ee.RemoveMacro("sync"), ErrMacroNotFound)
+	expect(t, ee.ApplyMacro("sync"), ErrMacroNotFound)
+
+	expect(t, ee.UseMacro("sync", []string{"a", "b"}, Sync), nil)
+	expect(t, ee.UseMacro("sync", []string{"b", "c"}, Sync), nil)
+	_, ok := ee.middlewares["a"]
+	expect(t, ok, false)
+	expect(t, len(ee.middlewares), 2)
+
+	expect(t, ee.UseMacro("bad", []string{"d", "["}, Sync), path.ErrBadPattern)
+	expect(t, len(ee.middlewares), 2)
+}
@@ -0,0 +1,91 @@
+package emitter
+
+import (
+	"path"
+	"strings"
+)
+
+// Matcher reports whether topic matches the pattern.
+type Matcher interface {
+	Match(pattern, topic string) (bool, error)
+}
+
+// PathMatcher matches topics via `path.Match` function,
+// it is the default matcher.
+type PathMatcher struct{}
+
+// Match implements Matcher interface.
+func (PathMatcher) Match(pattern, topic string) (bool, error) {
+	return path.Match(pattern, topic)
+}
+
+// HierarchicalMatcher treats `/` as a hierarchy separator. Every
+// segment is matched via `path.Match` and `**` segment matches zero
+// or more segments, so "a/**" matches "a/b/c".
+type HierarchicalMatcher struct{}
+
+// NewHierarchicalMatcher returns a new HierarchicalMatcher.
+func NewHierarchicalMatcher() HierarchicalMatcher {
+	return HierarchicalMatcher{}
+}
+
+// Match implements Matcher interface.
+func (HierarchicalMatcher) Match(pattern, topic string) (bool, error) {
+	return matchSegments(strings.Split(pattern, "/"), strings.Split(topic, "/"))
+}
+
+func matchSegments(pattern, topic []string) (bool, error) {
+	for len(pattern) > 0 {
+		if pattern[0] == "**" {
+			// try to match the rest of pattern with every tail of topic
+			for i := 0; i <= len(topic); i++ {
+				if matched, err := matchSegments(pattern[1:], topic[i:]); err != nil || matched {
+					return matched, err
+				}
+			}
+			return false, nil
+		}
+		if len(topic) == 0 {
+			return false, nil
+		}
+		if matched, err := path.Match(pattern[0], topic[0]); err != nil || !matched {
+			return false, err
+		}
+		pattern, topic = pattern[1:], topic[1:]
+	}
+	return len(topic) == 0, nil
+}
+
+type chainMatcher []Matcher
+
+// ChainMatcher returns a matcher which reports a match if any of the
+// given matchers does, they are tried in order. An error of any
+// matcher stops matching and is returned.
+func ChainMatcher(matchers ...Matcher) Matcher {
+	return chainMatcher(matchers)
+}
+
+// Match implements Matcher interface.
+func (c chainMatcher) Match(pattern, topic string) (bool, error) {
+	for _, m := range c {
+		if matched, err := m.Match(pattern, topic); err != nil || matched {
+			return matched, err
+		}
+	}
+	return false, nil
+}
+
+// SetMatcher sets the matcher used to match topics and patterns,
+// nil restores the default PathMatcher.
+func (e *Emitter) SetMatcher(m Matcher) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.matcher = m
+}
+
+func (e *Emitter) match(pattern, topic string) (bool, error) {
+	if e.matcher == nil {
+		return path.Match(pattern, topic)
+	}
+	return e.matcher.Match(pattern, topic)
+}
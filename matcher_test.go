@@ -0,0 +1,87 @@
+package emitter
+
+import (
+	"path"
+	"testing"
+)
+
+func TestHierarchicalMatcher(t *testing.T) {
+	m := NewHierarchicalMatcher()
+	cases := []struct {
+		pattern, topic string
+		matched        bool
+	}{
+		{"a/**", "a/b/c", true},
+		{"a/**", "a", true},
+		{"a/*/c", "a/b/c", true},
+		{"a/*/c", "a/b/d/c", false},
+		{"a/**/c", "a/b/d/c", true},
+		{"a/**/c", "a/c", true},
+		{"**", "a/b", true},
+		{"a/b*", "a/bc", true},
+		{"a/b", "a/b/c", false},
+		{"a/b/c", "a/b", false},
+	}
+	for _, c := range cases {
+		matched, err := m.Match(c.pattern, c.topic)
+		expect(t, err, nil)
+		if matched != c.matched {
+			t.Errorf("Match(%q, %q) = %v", c.pattern, c.topic, matched)
+		}
+	}
+
+	_, err := m.Match("a/\\", "a/b")
+	expect(t, err, path.ErrBadPattern)
+}
+
+type matcherFunc func(pattern, topic string) (bool, error)
+
+func (f matcherFunc) Match(pattern, topic string) (bool, error) {
+	return f(pattern, topic)
+}
+
+func TestChainMatcher(t *testing.T) {
+	var calls int
+	exact := matcherFunc(func(pattern, topic string) (bool, error) {
+		calls++
+		return pattern == topic, nil
+	})
+	m := ChainMatcher(exact, PathMatcher{}, NewHierarchicalMatcher())
+
+	matched, err := m.Match("a", "a")
+	expect(t, matched, true)
+	expect(t, err, nil)
+	matched, err = m.Match("a/*", "a/b")
+	expect(t, matched, true)
+	expect(t, err, nil)
+	matched, err = m.Match("a/**", "a/b/c")
+	expect(t, matched, true)
+	expect(t, err, nil)
+	matched, err = m.Match("b/**", "a/b/c")
+	expect(t, matched, false)
+	expect(t, err, nil)
+	expect(t, calls, 4)
+
+	// the error stops matching
+	matched, err = ChainMatcher(PathMatcher{}, exact).Match("\\", "\\")
+	expect(t, matched, false)
+	expect(t, err, path.ErrBadPattern)
+	expect(t, calls, 4)
+
+	matched, err = ChainMatcher().Match("a", "a")
+	expect(t, matched, false)
+	expect(t, err, nil)
+}
+
+func TestSetMatcher(t *testing.T) {
+	ee := New(1)
+	ee.SetMatcher(NewHierarchicalMatcher())
+	pipe := ee.On("org/**")
+	ee.Emit("org/team/user/created", 42).Wait()
+	expect(t, len(pipe), 1)
+	expect(t, (<-pipe).OriginalTopic, "org/team/user/created")
+
+	ee.SetMatcher(nil)
+	ee.Emit("org/team/user/created", 42).Wait()
+	expect(t, len(pipe), 0)
+}
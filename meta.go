@@ -0,0 +1,22 @@
+package emitter
+
+const (
+	// TopicSubscribe is a reserved topic to receive an event when a
+	// listener subscribes. Args are the topic and the number of its
+	// listeners.
+	TopicSubscribe = "$subscribe"
+	// TopicUnsubscribe is a reserved topic to receive an event when
+	// listeners unsubscribe. Args are the topic and the number of its
+	// listeners left.
+	TopicUnsubscribe = "$unsubscribe"
+)
+
+type metaChange struct {
+	meta  string
+	topic string
+	count int
+}
+
+func isMeta(topic string) bool {
+	return topic == TopicSubscribe || topic == TopicUnsubscribe
+}
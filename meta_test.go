@@ -0,0 +1,30 @@
+package emitter
+
+import "testing"
+
+func TestMetaEvents(t *testing.T) {
+	ee := New(10)
+	subscribe := ee.On(TopicSubscribe)
+	unsubscribe := ee.On(TopicUnsubscribe)
+	// meta topics don't trigger themselves
+	expect(t, len(subscribe), 0)
+
+	ee.On("test")
+	pipe := ee.On("test")
+	e := <-subscribe
+	expect(t, e.Topic, TopicSubscribe)
+	expect(t, e.String(0), "test")
+	expect(t, e.Int(1), 1)
+	e = <-subscribe
+	expect(t, e.String(0), "test")
+	expect(t, e.Int(1), 2)
+
+	ee.Off("test", pipe)
+	e = <-unsubscribe
+	expect(t, e.Topic, TopicUnsubscribe)
+	expect(t, e.String(0), "test")
+	expect(t, e.Int(1), 1)
+
+	ee.Off(TopicSubscribe)
+	expect(t, len(unsubscribe), 0)
+}
@@ -0,0 +1,397 @@
+package emitter
+
+import (
+	"container/list"
+	"reflect"
+	"sync"
+	"time"
+)
+
+// Case is a branch of the Switch middleware.
+type Case struct {
+	Predicate  func(Event) bool
+	Middleware func(*Event)
+}
+
+// Default returns a Case which matches any event, it should
+// be the last one.
+func Default(mw func(*Event)) Case {
+	return Case{
+		Predicate:  func(Event) bool { return true },
+		Middleware: mw,
+	}
+}
+
+// Switch returns a middleware which applies the middleware of the
+// first case which predicate is true for an event.
+func Switch(cases ...Case) func(*Event) {
+	return func(e *Event) {
+		for _, c := range cases {
+			if c.Predicate(*e) {
+				c.Middleware(e)
+				return
+			}
+		}
+	}
+}
+
+// EventObserver is notified about events.
+type EventObserver interface {
+	OnEvent(Event)
+}
+
+// ObserverMiddleware returns a middleware which notifies the
+// observer about an event without modifying it.
+func ObserverMiddleware(observer EventObserver) func(*Event) {
+	return func(e *Event) {
+		observer.OnEvent(*e)
+	}
+}
+
+// MinListeners returns a middleware which sets FlagVoid flag for an
+// event if the topic has fewer than n listeners. The middleware must
+// be registered on the same emitter.
+func (e *Emitter) MinListeners(n int) func(*Event) {
+	return func(event *Event) {
+		// middlewares are applied under the emitter lock
+		if len(e.listeners[event.Topic]) < n {
+			event.Flags = event.Flags | FlagVoid
+		}
+	}
+}
+
+// RateLimit returns a middleware which sets FlagVoid flag for an event
+// if more than rate events were passed for the topic within the last
+// per duration. It is intended to be registered via Use, as the state
+// is shared by all topics and listeners the middleware is applied to.
+func RateLimit(rate int, per time.Duration) func(*Event) {
+	var mu sync.Mutex
+	passed := make(map[string][]time.Time)
+
+	return func(e *Event) {
+		mu.Lock()
+		defer mu.Unlock()
+
+		now := time.Now()
+		window := passed[e.Topic]
+		// drop timestamps out of the window
+		i := 0
+		for i < len(window) && now.Sub(window[i]) >= per {
+			i++
+		}
+		window = window[i:]
+
+		if len(window) >= rate {
+			e.Flags = e.Flags | FlagVoid
+		} else {
+			window = append(window, now)
+		}
+		if len(window) == 0 {
+			delete(passed, e.Topic)
+		} else {
+			passed[e.Topic] = window
+		}
+	}
+}
+
+// DefaultLeakyBucketCapacity is the number of events
+// which can wait in the bucket of LeakyBucket.
+const DefaultLeakyBucketCapacity = 4
+
+// LeakyBucket returns a middleware which limits the rate of events to
+// rate events per second. An event exceeding the rate waits for its turn,
+// up to DefaultLeakyBucketCapacity events can wait, FlagVoid flag is set
+// for the rest. The event waits in background without blocking the
+// emitter, the delivery is done then, see LeakyBucketCapacity.
+func LeakyBucket(rate float64) func(*Event) {
+	return LeakyBucketCapacity(rate, DefaultLeakyBucketCapacity)
+}
+
+// LeakyBucketCapacity works exactly like LeakyBucket(see above) but
+// up to capacity events can wait in the bucket.
+func LeakyBucketCapacity(rate float64, capacity int) func(*Event) {
+	interval := time.Duration(float64(time.Second) / rate)
+	var mu sync.Mutex
+	var next time.Time
+
+	return func(e *Event) {
+		mu.Lock()
+		defer mu.Unlock()
+		now := time.Now()
+		if next.Before(now) {
+			next = now
+		}
+		wait := next.Sub(now)
+		if wait > interval*time.Duration(capacity) {
+			e.Flags = e.Flags | FlagVoid
+			return
+		}
+		next = next.Add(interval)
+		e.delay = wait
+	}
+}
+
+// TagFromTopic returns a middleware which sets the metadata of the event
+// to tags returned by tagFn for the emitted topic(see OriginalTopic).
+func TagFromTopic(tagFn func(topic string) map[string]string) func(*Event) {
+	return func(e *Event) {
+		tags := tagFn(e.OriginalTopic)
+		if len(tags) == 0 {
+			return
+		}
+		// the metadata is shared between listeners, copy it
+		meta := make(map[string]interface{}, len(e.Metadata)+len(tags))
+		for k, v := range e.Metadata {
+			meta[k] = v
+		}
+		for k, v := range tags {
+			meta[k] = v
+		}
+		e.Metadata = meta
+	}
+}
+
+// LRUEnrich returns a middleware which appends a value loaded by the
+// key of the event to the arguments. Values are kept in LRU cache of
+// cacheSize entries, loadFn is called on a cache miss only. The event
+// is left unchanged if loadFn fails, the error is not cached.
+func LRUEnrich(
+	cacheSize int,
+	keyFn func(Event) string,
+	loadFn func(string) (interface{}, error),
+) func(*Event) {
+	type entry struct {
+		key   string
+		value interface{}
+	}
+	var mu sync.Mutex
+	order := list.New()
+	cache := make(map[string]*list.Element, cacheSize)
+
+	return func(e *Event) {
+		key := keyFn(*e)
+
+		mu.Lock()
+		el, ok := cache[key]
+		if ok {
+			order.MoveToFront(el)
+		}
+		mu.Unlock()
+
+		var value interface{}
+		if ok {
+			value = el.Value.(*entry).value
+		} else {
+			var err error
+			if value, err = loadFn(key); err != nil {
+				return
+			}
+			mu.Lock()
+			if _, ok := cache[key]; !ok {
+				cache[key] = order.PushFront(&entry{key, value})
+				if order.Len() > cacheSize {
+					oldest := order.Back()
+					order.Remove(oldest)
+					delete(cache, oldest.Value.(*entry).key)
+				}
+			}
+			mu.Unlock()
+		}
+		// the arguments are shared between listeners, copy them
+		e.Args = append(e.Args[:len(e.Args):len(e.Args)], value)
+	}
+}
+
+// NilSafe returns a middleware which replaces nil arguments with zero
+// values, so type assertions of the arguments don't panic. The zero values
+// are of the types registered for the emitted topic, see RegisterEventType.
+// Nil arguments of unknown types are replaced with "", the Int, Float and
+// Bool helpers of the event return 0 and false for them.
+func NilSafe() func(*Event) {
+	return func(e *Event) {
+		var args []interface{}
+		for i, arg := range e.Args {
+			if arg != nil {
+				continue
+			}
+			if args == nil {
+				// the arguments are shared between listeners, copy them
+				args = append([]interface{}{}, e.Args...)
+			}
+			args[i] = ""
+			if t := e.argType(i); t != nil {
+				args[i] = reflect.Zero(t).Interface()
+			}
+		}
+		if args != nil {
+			e.Args = args
+		}
+	}
+}
+
+// DefaultAsyncChainTimeout is the time AsyncChain waits for the
+// functions to call next. It's short because the emitter is blocked
+// while the chain is running, see AsyncChain.
+const DefaultAsyncChainTimeout = 50 * time.Millisecond
+
+// AsyncChain returns a middleware which applies the functions in
+// sequence, a function calls next to pass the event to the next one,
+// possibly from another goroutine. The middleware returns when the last
+// function calls next, so every function has to call it. Set FlagVoid
+// flag to prevent the delivery. FlagVoid flag is set for the event if
+// the chain is not completed within DefaultAsyncChainTimeout.
+//
+// Note, middlewares are applied under the emitter lock, so the emitter
+// is blocked until the chain is completed or timed out: Emit, On, Off
+// and the rest of the methods wait for it. Keep the functions fast and
+// use AsyncChainTimeout with a short timeout.
+func AsyncChain(fns ...func(*Event, func())) func(*Event) {
+	return AsyncChainTimeout(DefaultAsyncChainTimeout, fns...)
+}
+
+// AsyncChainTimeout works exactly like AsyncChain(see above) but
+// with the timeout for the whole chain, the emitter is blocked
+// up to the timeout.
+func AsyncChainTimeout(timeout time.Duration, fns ...func(*Event, func())) func(*Event) {
+	return func(e *Event) {
+		// the functions which missed the timeout change the copy
+		c := *e
+		done := make(chan struct{})
+		var step func(i int)
+		step = func(i int) {
+			if i == len(fns) {
+				close(done)
+				return
+			}
+			var once sync.Once
+			fns[i](&c, func() {
+				once.Do(func() { step(i + 1) })
+			})
+		}
+		go step(0)
+
+		timer := time.NewTimer(timeout)
+		defer timer.Stop()
+		select {
+		case <-done:
+			*e = c
+		case <-timer.C:
+			e.Flags = e.Flags | FlagVoid
+		}
+	}
+}
+
+// VectorClock returns a middleware which ticks the vector clock of the
+// node and attaches its copy to the event as "vclock" metadata of
+// map[string]uint64 type, the node is attached as "vnode" metadata.
+// It shares the clock with VectorClockReceive of the same node created
+// before or after it, see VectorClockReceive.
+func VectorClock(nodeID string) func(*Event) {
+	send, _ := pairedVectorClock(nodeID, true)
+	return send
+}
+
+// VectorClockReceive returns a middleware which merges the vector clock
+// of the event sent by another node into the clock of the node. FlagVoid
+// flag is set for an event which arrives before the events it causally
+// depends on, the clock is not changed then. Events without the clock
+// and events of the node itself are passed as is. The clock is shared
+// with VectorClock of the same node, the middlewares are paired in order
+// of creation, so every VectorClock call for the node has to be matched
+// by a VectorClockReceive call.
+func VectorClockReceive(nodeID string) func(*Event) {
+	_, receive := pairedVectorClock(nodeID, false)
+	return receive
+}
+
+// unpairedClocks keeps the clocks which half is not created yet, the
+// clock is removed when the other half takes it.
+var (
+	unpairedMu     sync.Mutex
+	unpairedClocks = map[string]unpairedClock{}
+)
+
+type unpairedClock struct {
+	send, receive func(*Event)
+	// isSend reports which half is taken
+	isSend bool
+}
+
+// pairedVectorClock returns the middlewares of the clock of the node which
+// half is not taken yet, a new clock is created if there is no such one.
+func pairedVectorClock(nodeID string, isSend bool) (send, receive func(*Event)) {
+	unpairedMu.Lock()
+	defer unpairedMu.Unlock()
+	if c, ok := unpairedClocks[nodeID]; ok && c.isSend != isSend {
+		delete(unpairedClocks, nodeID)
+		return c.send, c.receive
+	}
+	send, receive = newVectorClock(nodeID)
+	unpairedClocks[nodeID] = unpairedClock{send: send, receive: receive, isSend: isSend}
+	return send, receive
+}
+
+// newVectorClock returns the pair of VectorClock and
+// VectorClockReceive middlewares sharing the clock.
+func newVectorClock(nodeID string) (send, receive func(*Event)) {
+	var mu sync.Mutex
+	local := map[string]uint64{}
+
+	send = func(e *Event) {
+		mu.Lock()
+		local[nodeID]++
+		clock := make(map[string]uint64, len(local))
+		for k, v := range local {
+			clock[k] = v
+		}
+		mu.Unlock()
+		e.SetMeta("vclock", clock)
+		e.SetMeta("vnode", nodeID)
+	}
+
+	receive = func(e *Event) {
+		v, _ := e.Meta("vclock")
+		clock, ok := v.(map[string]uint64)
+		n, _ := e.Meta("vnode")
+		sender, _ := n.(string)
+		if !ok || sender == "" || sender == nodeID {
+			return
+		}
+
+		mu.Lock()
+		defer mu.Unlock()
+		for k, t := range clock {
+			if (k == sender && t != local[k]+1) || (k != sender && t > local[k]) {
+				e.Flags = e.Flags | FlagVoid
+				return
+			}
+		}
+		for k, t := range clock {
+			if t > local[k] {
+				local[k] = t
+			}
+		}
+	}
+	return send, receive
+}
+
+// SLAMiddleware returns a function which calls alertFn if the time passed
+// since the event was emitted exceeds maxLatencyMs milliseconds, the event
+// is not changed. It's meant to be applied to received events, since the
+// latency is about zero when middlewares are applied on emitting:
+//
+//	sla := emitter.SLAMiddleware(100, alert)
+//	for event := range e.On("order.*") {
+//		sla(&event)
+//		// handle the event
+//	}
+func SLAMiddleware(maxLatencyMs int, alertFn func(topic string, latencyMs int64)) func(*Event) {
+	return func(e *Event) {
+		if e.Timestamp.IsZero() {
+			return
+		}
+		if latency := time.Since(e.Timestamp).Milliseconds(); latency > int64(maxLatencyMs) {
+			alertFn(e.OriginalTopic, latency)
+		}
+	}
+}
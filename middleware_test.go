@@ -0,0 +1,393 @@
+package emitter
+
+import (
+	"errors"
+	"regexp"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestSwitch(t *testing.T) {
+	ee := New(10)
+	ee.Use("*", Sync)
+	pipe := ee.On("test", Switch(
+		Case{
+			Predicate:  func(e Event) bool { return e.Int(0) < 0 },
+			Middleware: Void,
+		},
+		Case{
+			Predicate: func(e Event) bool { return e.Int(0) < 10 },
+			Middleware: func(e *Event) {
+				e.Args = append(e.Args, "small")
+			},
+		},
+		Default(func(e *Event) {
+			e.Args = append(e.Args, "large")
+		}),
+	))
+
+	ee.Emit("test", -1).Wait()
+	ee.Emit("test", 5).Wait()
+	ee.Emit("test", 50).Wait()
+
+	expect(t, len(pipe), 2)
+	e := <-pipe
+	expect(t, e.Int(0), 5)
+	expect(t, e.String(1), "small")
+	e = <-pipe
+	expect(t, e.Int(0), 50)
+	expect(t, e.String(1), "large")
+}
+
+type mockObserver struct {
+	mu     sync.Mutex
+	events []Event
+}
+
+func (o *mockObserver) OnEvent(e Event) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.events = append(o.events, e)
+}
+
+func TestObserverMiddleware(t *testing.T) {
+	ee := New(3)
+	observer := &mockObserver{}
+	pipe := ee.On("test", ObserverMiddleware(observer))
+	ee.On("other")
+
+	for i := 0; i < 3; i++ {
+		ee.Emit("test", i).Wait()
+	}
+	ee.Emit("other").Wait()
+
+	expect(t, len(observer.events), 3)
+	for i := 0; i < 3; i++ {
+		e := <-pipe
+		expect(t, e.Int(0), i)
+		expect(t, observer.events[i].Int(0), i)
+	}
+}
+
+func TestMinListeners(t *testing.T) {
+	ee := New(1)
+	ee.Use("test", ee.MinListeners(3))
+	pipe := ee.On("test")
+
+	ee.Emit("test").Wait()
+	expect(t, len(pipe), 0)
+
+	pipe2 := ee.On("test")
+	pipe3 := ee.On("test")
+	ee.Emit("test").Wait()
+	expect(t, len(pipe), 1)
+	expect(t, len(pipe2), 1)
+	expect(t, len(pipe3), 1)
+}
+
+func TestRateLimit(t *testing.T) {
+	ee := New(10)
+	ee.Use("*", RateLimit(2, 50*time.Millisecond))
+	pipe := ee.On("test")
+	pipe2 := ee.On("other")
+
+	for i := 0; i < 3; i++ {
+		ee.Emit("test", i).Wait()
+	}
+	ee.Emit("other").Wait()
+	expect(t, len(pipe), 2)
+	expect(t, len(pipe2), 1)
+
+	time.Sleep(60 * time.Millisecond)
+	ee.Emit("test", 3).Wait()
+	expect(t, len(pipe), 3)
+	expect(t, (<-pipe).Int(0), 0)
+	expect(t, (<-pipe).Int(0), 1)
+	expect(t, (<-pipe).Int(0), 3)
+}
+
+func TestLeakyBucket(t *testing.T) {
+	const interval = 20 * time.Millisecond
+	ee := New(20)
+	ee.Use("test", LeakyBucket(float64(time.Second/interval)))
+	pipe := ee.On("test")
+	other := ee.On("other")
+
+	start := time.Now()
+	handles := make([]*EmitHandle, 20)
+	for i := range handles {
+		handles[i] = ee.Emit("test", i)
+	}
+	// waiting events don't block the emitter
+	ee.Emit("other").Wait()
+	<-other
+	expect(t, time.Since(start) < interval, true)
+
+	for _, h := range handles {
+		h.Wait()
+	}
+	// one event passes immediately and four wait in the bucket
+	expect(t, len(pipe), 5)
+	for i := 0; i < 5; i++ {
+		expect(t, (<-pipe).Int(0), i)
+	}
+	expect(t, time.Since(start) >= 4*interval, true)
+
+	// the bucket is leaked
+	time.Sleep(5 * interval)
+	ee.Emit("test", 20).Wait()
+	expect(t, (<-pipe).Int(0), 20)
+
+	// the bucket capacity can be set
+	mw := LeakyBucketCapacity(1, 0)
+	e := &Event{}
+	mw(e)
+	expect(t, e.Flags, Flag(0))
+	mw(e)
+	expect(t, e.Flags, FlagVoid)
+}
+
+func TestTagFromTopic(t *testing.T) {
+	re := regexp.MustCompile(`^org/(\d+)/user/(\d+)/`)
+	ee := New(1)
+	ee.Use("org/*/user/*/*", TagFromTopic(func(topic string) map[string]string {
+		m := re.FindStringSubmatch(topic)
+		if m == nil {
+			return nil
+		}
+		return map[string]string{"org_id": m[1], "user_id": m[2]}
+	}))
+	pipe := ee.On("org/*/user/*/*")
+	ee.EmitWithToken("secret", "org/42/user/7/login").Wait()
+	e := <-pipe
+	org, _ := e.Meta("org_id")
+	user, _ := e.Meta("user_id")
+	token, _ := e.Meta("token")
+	expect(t, org, "42")
+	expect(t, user, "7")
+	expect(t, token, "secret")
+}
+
+func TestLRUEnrich(t *testing.T) {
+	var loads []string
+	mw := LRUEnrich(2, func(e Event) string {
+		return e.String(0)
+	}, func(key string) (interface{}, error) {
+		loads = append(loads, key)
+		if key == "bad" {
+			return nil, errors.New("not found")
+		}
+		return "user:" + key, nil
+	})
+
+	ee := New(5)
+	ee.Use("*", mw)
+	pipe := ee.On("test")
+	for i := 0; i < 5; i++ {
+		ee.Emit("test", "42").Wait()
+	}
+	expect(t, len(loads), 1)
+	for i := 0; i < 5; i++ {
+		e := <-pipe
+		expect(t, len(e.Args), 2)
+		expect(t, e.String(1), "user:42")
+	}
+
+	// the least recently used key is evicted
+	ee.Emit("test", "1").Wait()
+	ee.Emit("test", "42").Wait()
+	ee.Emit("test", "2").Wait()
+	ee.Emit("test", "42").Wait()
+	expect(t, len(loads), 3)
+	ee.Emit("test", "1").Wait()
+	expect(t, len(loads), 4)
+	for len(pipe) != 0 {
+		<-pipe
+	}
+
+	// errors are not cached
+	ee.Emit("test", "bad").Wait()
+	ee.Emit("test", "bad").Wait()
+	expect(t, len(loads), 6)
+	expect(t, len((<-pipe).Args), 1)
+}
+
+func TestNilSafe(t *testing.T) {
+	ee := New(2)
+	ee.RegisterEventType("test", 1, "", true, 1.5)
+	pipe := ee.On("*", NilSafe())
+	raw := ee.On("test")
+	ee.Emit("test", nil, nil, nil, nil, nil, 42).Wait()
+
+	e := <-pipe
+	expect(t, e.Args[0].(int), 0)
+	expect(t, e.Args[1].(string), "")
+	expect(t, e.Args[2].(bool), false)
+	expect(t, e.Args[3].(float64), 0.0)
+	expect(t, e.Args[4].(string), "")
+	expect(t, e.Args[5].(int), 42)
+	// the arguments of other listeners are not changed
+	expect(t, (<-raw).Args[0], nil)
+
+	// defaults are used for unregistered topics
+	ee.Emit("other", nil).Wait()
+	e = <-pipe
+	expect(t, e.Args[0].(string), "")
+	expect(t, e.Int(0), 0)
+	expect(t, e.Bool(0), false)
+
+	ee.RegisterEventType("test")
+	ee.Emit("test", nil).Wait()
+	expect(t, (<-pipe).Args[0].(string), "")
+	<-raw
+}
+
+func TestAsyncChain(t *testing.T) {
+	var mu sync.Mutex
+	var order []string
+	step := func(key string) func(*Event, func()) {
+		return func(e *Event, next func()) {
+			signal := make(chan struct{})
+			go func() {
+				<-signal
+				mu.Lock()
+				order = append(order, key)
+				mu.Unlock()
+				e.SetMeta(key, len(e.Metadata))
+				next()
+			}()
+			time.Sleep(time.Millisecond)
+			close(signal)
+		}
+	}
+
+	ee := New(1)
+	pipe := ee.On("test", AsyncChain(step("a"), step("b"), step("c")))
+	ee.Emit("test").Wait()
+	e := <-pipe
+	for i, key := range []string{"a", "b", "c"} {
+		v, _ := e.Meta(key)
+		expect(t, v, i)
+		expect(t, order[i], key)
+	}
+
+	// next can be called several times
+	void := ee.On("void", AsyncChain(func(e *Event, next func()) {
+		go func() {
+			e.Flags = e.Flags | FlagVoid
+			next()
+			next()
+		}()
+	}))
+	ee.Emit("void").Wait()
+	expect(t, len(void), 0)
+
+	// the event is not delivered if the chain is stuck
+	stuck := ee.On("stuck", AsyncChainTimeout(10*time.Millisecond, func(e *Event, next func()) {}))
+	ee.Emit("stuck").Wait()
+	expect(t, len(stuck), 0)
+}
+
+func TestAsyncChainBlocking(t *testing.T) {
+	ee := New(1)
+	subscribe := func() time.Duration {
+		start := time.Now()
+		ee.On("other")
+		return time.Since(start)
+	}
+
+	// the emitter is released as soon as the chain is completed
+	started := make(chan struct{})
+	ee.On("fast", AsyncChainTimeout(time.Hour, func(e *Event, next func()) {
+		close(started)
+		go func() {
+			time.Sleep(10 * time.Millisecond)
+			next()
+		}()
+	}))
+	h := ee.Emit("fast")
+	<-started
+	if d := subscribe(); d > time.Second {
+		t.Errorf("On was blocked for %v", d)
+	}
+	h.Wait()
+
+	// the stuck chain blocks the emitter for the default timeout only
+	started = make(chan struct{})
+	ee.On("stuck", AsyncChain(func(e *Event, next func()) { close(started) }))
+	h = ee.Emit("stuck")
+	<-started
+	if d := subscribe(); d > 10*DefaultAsyncChainTimeout {
+		t.Errorf("On was blocked for %v", d)
+	}
+	h.Wait()
+}
+
+func TestVectorClock(t *testing.T) {
+	sendA, receiveA := VectorClock("vc-a"), VectorClockReceive("vc-a")
+	receiveB, sendB := VectorClockReceive("vc-b"), VectorClock("vc-b")
+	isVoid := func(e *Event) bool { return (e.Flags | FlagVoid) == e.Flags }
+
+	e1, e2 := &Event{}, &Event{}
+	sendA(e1)
+	sendA(e2)
+	v, _ := e2.Meta("vclock")
+	expect(t, v.(map[string]uint64)["vc-a"], uint64(2))
+
+	// e2 arrives before its causal predecessor e1
+	receiveB(e2)
+	expect(t, isVoid(e2), true)
+	receiveB(e1)
+	expect(t, isVoid(e1), false)
+
+	// the reply of B depends on e1 which A knows already
+	e3 := &Event{}
+	sendB(e3)
+	v, _ = e3.Meta("vclock")
+	expect(t, v.(map[string]uint64)["vc-a"], uint64(1))
+	expect(t, v.(map[string]uint64)["vc-b"], uint64(1))
+	receiveA(e3)
+	expect(t, isVoid(e3), false)
+
+	// the events of the node itself and without the clock are passed
+	receiveA(e1)
+	expect(t, isVoid(e1), false)
+	e4 := &Event{}
+	receiveA(e4)
+	expect(t, isVoid(e4), false)
+
+	// the delayed event is delivered after the retransmission
+	e2.Flags = FlagReset
+	receiveB(e2)
+	expect(t, isVoid(e2), false)
+}
+
+func TestSLAMiddleware(t *testing.T) {
+	type alert struct {
+		topic   string
+		latency int64
+	}
+	var alerts []alert
+	sla := SLAMiddleware(20, func(topic string, latency int64) {
+		alerts = append(alerts, alert{topic, latency})
+	})
+
+	ee := New(1)
+	pipe := ee.On("test")
+	ee.Emit("test", 1).Wait()
+	e := <-pipe
+	sla(&e)
+	expect(t, len(alerts), 0)
+
+	start := time.Now()
+	ee.Emit("test", 2).Wait()
+	time.Sleep(30 * time.Millisecond)
+	e = <-pipe
+	sla(&e)
+	expect(t, e.Int(0), 2)
+	expect(t, len(alerts), 1)
+	expect(t, alerts[0].topic, "test")
+	expect(t, alerts[0].latency >= 30, true)
+	expect(t, alerts[0].latency <= time.Since(start).Milliseconds(), true)
+}
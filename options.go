@@ -0,0 +1,22 @@
+package emitter
+
+// Option configures an emitter created by New.
+type Option func(*Emitter)
+
+// WithPendingQueueSize sets the number of events EmitOrQueue keeps
+// while there are no listeners, n must be positive.
+func WithPendingQueueSize(n int) Option {
+	return func(e *Emitter) {
+		e.pending.size = n
+	}
+}
+
+// WithTrieIndex makes the emitter keep topics in a prefix tree keyed on
+// path segments, so emitting checks only topics which can match instead
+// of all ones. It's useful for a large number of topics, and it is not
+// applied if a custom matcher is set.
+func WithTrieIndex() Option {
+	return func(e *Emitter) {
+		e.index = newTopicIndex()
+	}
+}
@@ -0,0 +1,69 @@
+package emitter
+
+// OverflowStrategy describes what to do with an event
+// if the listener channel is blocked.
+type OverflowStrategy int
+
+const (
+	// OverflowBlock waits until the channel is unblocked, it is
+	// the default strategy and it respects flags set by middlewares.
+	OverflowBlock OverflowStrategy = iota
+	// OverflowDrop skips the event, same as FlagSkip.
+	OverflowDrop
+	// OverflowClose drops the listener, same as FlagClose.
+	OverflowClose
+	// OverflowDropOldest drops the oldest event in the channel
+	// to make room for the new one. The dropped event is counted
+	// and passed to the dead letter handler with ErrChannelFull.
+	OverflowDropOldest
+)
+
+func (s OverflowStrategy) String() string {
+	switch s {
+	case OverflowBlock:
+		return "block"
+	case OverflowDrop:
+		return "drop"
+	case OverflowClose:
+		return "close"
+	case OverflowDropOldest:
+		return "drop oldest"
+	}
+	return "unknown"
+}
+
+// OnWithOverflow returns a channel that will receive events with the
+// listener capacity and overflow strategy specified. As optional third
+// argument it takes middlewares.
+func (e *Emitter) OnWithOverflow(topic string, capacity uint, strategy OverflowStrategy, middlewares ...func(*Event)) <-chan Event {
+	return e.subscribe(topic, newListenerWithOverflow(capacity, strategy, middlewares...))
+}
+
+func newListenerWithOverflow(capacity uint, strategy OverflowStrategy, middlewares ...func(*Event)) listener {
+	l := newListener(capacity, middlewares...)
+	l.overflow = strategy
+	return l
+}
+
+// push sends the event to the listener channel according to
+// the overflow strategy, it must be called under the lock.
+func (l listener) push(e *Emitter, done chan struct{}, event *Event) (sent, remove bool, err error) {
+	switch l.overflow {
+	case OverflowDrop:
+		event.Flags = event.Flags | FlagSkip
+	case OverflowClose:
+		event.Flags = event.Flags | FlagClose
+	case OverflowDropOldest:
+		event.Flags = event.Flags | FlagSkip
+		sent, remove, err = pushEvent(done, l.ch, event)
+		if err != ErrChannelFull {
+			return
+		}
+		select {
+		case oldest := <-l.ch:
+			e.report(oldest, false, ErrChannelFull)
+		default:
+		}
+	}
+	return pushEvent(done, l.ch, event)
+}
@@ -0,0 +1,91 @@
+package emitter
+
+import (
+	"testing"
+	"time"
+)
+
+func TestOverflowBlock(t *testing.T) {
+	ee := New(0)
+	pipe := ee.OnWithOverflow("test", 1, OverflowBlock)
+	ee.Emit("test", 1).Wait()
+
+	h := ee.Emit("test", 2)
+	select {
+	case <-h.Done():
+		t.Error("the event was not blocked")
+	case <-time.After(10 * time.Millisecond):
+		h.Cancel()
+	}
+	expect(t, (<-pipe).Int(0), 1)
+	expect(t, len(pipe), 0)
+}
+
+func TestOverflowDrop(t *testing.T) {
+	ee := New(0)
+	pipe := ee.OnWithOverflow("test", 1, OverflowDrop)
+	ee.Emit("test", 1).Wait()
+	ee.Emit("test", 2).Wait()
+	expect(t, len(pipe), 1)
+	expect(t, (<-pipe).Int(0), 1)
+	expect(t, len(ee.Listeners("test")), 1)
+}
+
+func TestOverflowClose(t *testing.T) {
+	ee := New(0)
+	pipe := ee.OnWithOverflow("test", 1, OverflowClose)
+	ee.Emit("test", 1).Wait()
+	ee.Emit("test", 2).Wait()
+	expect(t, (<-pipe).Int(0), 1)
+	_, ok := <-pipe
+	expect(t, ok, false)
+	expect(t, len(ee.Listeners("test")), 0)
+}
+
+func TestOverflowDropOldest(t *testing.T) {
+	ee := New(0)
+	pipe := ee.OnWithOverflow("test", 2, OverflowDropOldest)
+	ee.Emit("test", 1).Wait()
+	ee.Emit("test", 2).Wait()
+	ee.Emit("test", 3).Wait()
+	expect(t, len(pipe), 2)
+	expect(t, (<-pipe).Int(0), 2)
+	expect(t, (<-pipe).Int(0), 3)
+}
+
+func TestOverflowDropOldestDeadLetter(t *testing.T) {
+	ee := New(0)
+	dead := make(chan Event, 1)
+	reasons := make(chan error, 1)
+	ee.SetDeadLetterHandler(func(event Event, reason error) {
+		dead <- event
+		reasons <- reason
+	})
+	pipe := ee.OnWithOverflow("test", 1, OverflowDropOldest)
+	ee.Emit("test", 1).Wait()
+	expect(t, ee.Emit("test", 2).Wait(), nil)
+
+	expect(t, (<-dead).Int(0), 1)
+	expect(t, <-reasons, ErrChannelFull)
+	expect(t, ee.Stats(false).TotalDrops, uint64(1))
+	expect(t, ee.Stats(false).TotalDeliveries, uint64(2))
+	expect(t, (<-pipe).Int(0), 2)
+}
+
+func TestListenerStats(t *testing.T) {
+	ee := New(0)
+	pipe := ee.OnWithOverflow("test", 2, OverflowDropOldest)
+	ee.Emit("test", 1).Wait()
+
+	s, ok := ee.ListenerStats(pipe)
+	expect(t, ok, true)
+	expect(t, s.Topic, "test")
+	expect(t, s.Len, 1)
+	expect(t, s.Cap, 2)
+	expect(t, s.Overflow, OverflowDropOldest)
+	expect(t, s.Overflow.String(), "drop oldest")
+
+	ee.Off("test")
+	_, ok = ee.ListenerStats(pipe)
+	expect(t, ok, false)
+}
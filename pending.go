@@ -0,0 +1,134 @@
+package emitter
+
+import (
+	"errors"
+	"time"
+)
+
+// DefaultPendingQueueSize is the number of events EmitOrQueue keeps
+// if the size is not set via WithPendingQueueSize.
+const DefaultPendingQueueSize = 64
+
+// errQueued aborts the emitting of an event kept in the pending queue.
+var errQueued = errors.New("emitter: event is queued")
+
+type pendingEvent struct {
+	topic     string
+	args      []interface{}
+	meta      map[string]interface{}
+	timestamp time.Time
+}
+
+// pendingQueue is a ring buffer of events which were emitted while
+// there were no listeners. The oldest event is dropped when it is full.
+type pendingQueue struct {
+	size  int
+	buf   []pendingEvent
+	start int
+	n     int
+}
+
+func (q *pendingQueue) push(event pendingEvent) {
+	if q.buf == nil {
+		size := q.size
+		if size <= 0 {
+			size = DefaultPendingQueueSize
+		}
+		q.buf = make([]pendingEvent, size)
+	}
+	i := (q.start + q.n) % len(q.buf)
+	q.buf[i] = event
+	if q.n < len(q.buf) {
+		q.n++
+	} else {
+		q.start = (q.start + 1) % len(q.buf)
+	}
+}
+
+// take removes and returns events which topics match the
+// listener topic, the order is kept.
+func (q *pendingQueue) take(topic string, match func(pattern, topic string) (bool, error)) []pendingEvent {
+	var taken, rest []pendingEvent
+	for i := 0; i < q.n; i++ {
+		event := q.buf[(q.start+i)%len(q.buf)]
+		if matched, _ := match(event.topic, topic); matched {
+			taken = append(taken, event)
+		} else if matched, _ := match(topic, event.topic); matched {
+			taken = append(taken, event)
+		} else {
+			rest = append(rest, event)
+		}
+	}
+	if len(taken) == 0 {
+		return nil
+	}
+	for i := range q.buf {
+		q.buf[i] = pendingEvent{}
+	}
+	q.start = 0
+	q.n = copy(q.buf, rest)
+	return taken
+}
+
+// EmitOrQueue emits an event like Emit if there are listeners which
+// are covered by topic, otherwise the event is kept in the pending
+// queue. Pending events are sent to the first matching listener
+// before any other events.
+func (e *Emitter) EmitOrQueue(topic string, args ...interface{}) *EmitHandle {
+	h, err := e.publish(func(emissions []emission) error {
+		if len(emissions[0].match) != 0 {
+			return nil
+		}
+		e.emitted.Add(1)
+		e.pending.push(pendingEvent{topic: topic, args: args, timestamp: time.Now()})
+		return errQueued
+	}, emission{topic: topic, args: args})
+	if err == errQueued {
+		// queuing is not a failure of the emitting
+		return finishedHandle(nil)
+	}
+	return h
+}
+
+// flushPending sends the pending events to the just subscribed listener,
+// it must be called with locked mutex and it unlocks the mutex.
+func (e *Emitter) flushPending(topic string, l listener, pending []pendingEvent, count int) {
+	done := make(chan struct{})
+	middlewares := e.getMiddlewares(topic)
+
+	var remove bool
+	for _, p := range pending {
+		event := Event{
+			Topic:         topic,
+			OriginalTopic: p.topic,
+			Args:          p.args,
+			Metadata:      p.meta,
+			Timestamp:     p.timestamp,
+			types:         e.eventTypes[p.topic],
+		}
+		applyMiddlewares(&event, middlewares)
+		applyMiddlewares(&event, l.middlewares)
+		if event.err != nil {
+			e.fail(topic, event.err)
+		}
+		if (event.Flags | FlagVoid) == event.Flags {
+			continue
+		}
+
+		var sent bool
+		var err error
+		sent, remove, err = l.push(e, done, &event)
+		e.report(event, sent, err)
+		if remove {
+			break
+		}
+	}
+	e.mu.Unlock()
+
+	if !isMeta(topic) {
+		e.subscribersChanged(TopicSubscribe, topic, count)
+	}
+	if remove {
+		e.Off(topic, l.ch)
+	}
+}
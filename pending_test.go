@@ -0,0 +1,47 @@
+package emitter
+
+import "testing"
+
+func TestEmitOrQueue(t *testing.T) {
+	ee := New(10)
+	for i := 1; i <= 3; i++ {
+		ee.EmitOrQueue("test", i).Wait()
+	}
+	ee.EmitOrQueue("other", 0).Wait()
+
+	pipe := ee.On("test")
+	ee.Emit("test", 4).Wait()
+	for i := 1; i <= 4; i++ {
+		expect(t, (<-pipe).Int(0), i)
+	}
+
+	// there are listeners now
+	ee.EmitOrQueue("test", 5).Wait()
+	expect(t, (<-pipe).Int(0), 5)
+	expect(t, (<-ee.On("*")).OriginalTopic, "other")
+}
+
+func TestEmitOrQueueUnbuffered(t *testing.T) {
+	ee := New(0)
+	ee.EmitOrQueue("test", 1).Wait()
+	ee.EmitOrQueue("test", 2).Wait()
+
+	pipe := ee.On("test")
+	go ee.Emit("test", 3)
+	for i := 1; i <= 3; i++ {
+		expect(t, (<-pipe).Int(0), i)
+	}
+}
+
+func TestPendingQueueOverflow(t *testing.T) {
+	ee := New(10, WithPendingQueueSize(2))
+	for i := 1; i <= 3; i++ {
+		ee.EmitOrQueue("test", i).Wait()
+	}
+
+	pipe := ee.On("test")
+	ee.Emit("test", 4).Wait()
+	expect(t, (<-pipe).Int(0), 2)
+	expect(t, (<-pipe).Int(0), 3)
+	expect(t, (<-pipe).Int(0), 4)
+}
@@ -0,0 +1,55 @@
+//go:build protovalidate
+
+package emitter
+
+import (
+	"encoding/json"
+	"errors"
+
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/types/dynamicpb"
+)
+
+// ErrNoProtoMessage is attached by ProtoValidate
+// to an event without arguments.
+var ErrNoProtoMessage = errors.New("emitter: event has no message to validate")
+
+// ProtoValidate returns a middleware which checks that the first argument
+// of an event is a message of the descriptor with all the required fields
+// set. The argument is encoded to JSON unless it's a proto.Message already.
+// FlagVoid flag is set for an invalid event and the error is attached as
+// "error" metadata. The middleware is built with the protovalidate build
+// tag only, because it depends on google.golang.org/protobuf.
+func ProtoValidate(descriptor protoreflect.MessageDescriptor) func(*Event) {
+	return func(e *Event) {
+		if err := protoValidate(descriptor, e.Args); err != nil {
+			e.Flags = e.Flags | FlagVoid
+			e.SetMeta("error", err)
+		}
+	}
+}
+
+func protoValidate(descriptor protoreflect.MessageDescriptor, args []interface{}) error {
+	if len(args) == 0 {
+		return ErrNoProtoMessage
+	}
+	var data []byte
+	var err error
+	if m, ok := args[0].(proto.Message); ok {
+		data, err = protojson.MarshalOptions{AllowPartial: true}.Marshal(m)
+	} else {
+		data, err = json.Marshal(args[0])
+	}
+	if err != nil {
+		return err
+	}
+
+	msg := dynamicpb.NewMessage(descriptor)
+	// required fields are checked separately to report them explicitly
+	if err := (protojson.UnmarshalOptions{AllowPartial: true}).Unmarshal(data, msg); err != nil {
+		return err
+	}
+	return proto.CheckInitialized(msg)
+}
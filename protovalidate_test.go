@@ -0,0 +1,68 @@
+//go:build protovalidate
+
+package emitter
+
+import (
+	"testing"
+
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protodesc"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/types/descriptorpb"
+)
+
+// userDescriptor describes the message:
+//
+//	syntax = "proto2";
+//	message User {
+//	  required int64 id = 1;
+//	  optional string name = 2;
+//	}
+func userDescriptor(t *testing.T) protoreflect.MessageDescriptor {
+	fd, err := protodesc.NewFile(&descriptorpb.FileDescriptorProto{
+		Name:    proto.String("user.proto"),
+		Package: proto.String("emitter.test"),
+		Syntax:  proto.String("proto2"),
+		MessageType: []*descriptorpb.DescriptorProto{{
+			Name: proto.String("User"),
+			Field: []*descriptorpb.FieldDescriptorProto{{
+				Name:     proto.String("id"),
+				JsonName: proto.String("id"),
+				Number:   proto.Int32(1),
+				Label:    descriptorpb.FieldDescriptorProto_LABEL_REQUIRED.Enum(),
+				Type:     descriptorpb.FieldDescriptorProto_TYPE_INT64.Enum(),
+			}, {
+				Name:     proto.String("name"),
+				JsonName: proto.String("name"),
+				Number:   proto.Int32(2),
+				Label:    descriptorpb.FieldDescriptorProto_LABEL_OPTIONAL.Enum(),
+				Type:     descriptorpb.FieldDescriptorProto_TYPE_STRING.Enum(),
+			}},
+		}},
+	}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return fd.Messages().ByName("User")
+}
+
+func TestProtoValidate(t *testing.T) {
+	ee := New(3)
+	pipe := ee.On("user", ProtoValidate(userDescriptor(t)))
+
+	ee.Emit("user", map[string]interface{}{"id": 42, "name": "john"}).Wait()
+	expect(t, len(pipe), 1)
+	<-pipe
+
+	// missing required field, unknown field and no message
+	ee.Emit("user", map[string]interface{}{"name": "john"}).Wait()
+	ee.Emit("user", map[string]interface{}{"id": 42, "email": "john@example.com"}).Wait()
+	ee.Emit("user").Wait()
+	expect(t, len(pipe), 0)
+
+	e := &Event{Args: []interface{}{map[string]interface{}{"name": "john"}}}
+	ProtoValidate(userDescriptor(t))(e)
+	expect(t, (e.Flags|FlagVoid) == e.Flags, true)
+	err, _ := e.Meta("error")
+	expect(t, err != nil, true)
+}
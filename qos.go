@@ -0,0 +1,144 @@
+package emitter
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"sync"
+	"time"
+)
+
+// QoSLevel describes delivery guarantees for a topic.
+type QoSLevel int
+
+const (
+	// QoSAtMostOnce sends an event once, it is the default level.
+	QoSAtMostOnce QoSLevel = iota
+	// QoSAtLeastOnce sends an event again if the sending failed
+	// because the listener channel was blocked.
+	QoSAtLeastOnce
+	// QoSExactlyOnce works as QoSAtLeastOnce and an event is not sent
+	// to a listener which has received an event with the same ID. The
+	// ID is the "id" metadata of string type, a random one is attached
+	// to the event if it has none, so the events forwarded with the
+	// metadata, e.g. by Redirect, are deduplicated.
+	QoSExactlyOnce
+)
+
+// ErrInvalidQoS is returned by SetTopicQoS for unknown QoS level.
+var ErrInvalidQoS = errors.New("emitter: invalid QoS level")
+
+const (
+	qosRetries       = 5
+	qosRetryInterval = time.Millisecond
+	// qosDedupWindow is the number of the last event IDs kept
+	// per listener of a topic with QoSExactlyOnce.
+	qosDedupWindow = 1024
+)
+
+// SetTopicQoS sets delivery guarantees for the listeners of the topic.
+// Failed sending is retried up to 5 times with an exponential backoff
+// for QoSAtLeastOnce and QoSExactlyOnce. The Emit handle is done
+// after the last attempt.
+func (e *Emitter) SetTopicQoS(topic string, qos QoSLevel) error {
+	if qos < QoSAtMostOnce || qos > QoSExactlyOnce {
+		return ErrInvalidQoS
+	}
+	e.mu.Lock()
+	e.init()
+	defer e.mu.Unlock()
+
+	if qos == QoSAtMostOnce {
+		delete(e.qos, topic)
+		return nil
+	}
+	if e.qos == nil {
+		e.qos = make(map[string]QoSLevel)
+	}
+	e.qos[topic] = qos
+	return nil
+}
+
+// retry sends the event again until it is sent or the number
+// of attempts is exceeded or the emitting is canceled.
+func (e *Emitter) retry(
+	h *EmitHandle,
+	lstnr listener,
+	event Event,
+	wg *sync.WaitGroup,
+	reports chan DeliveryReport,
+) {
+	defer wg.Done()
+
+	var sent, remove bool
+	err := ErrChannelFull
+	interval := qosRetryInterval
+	for i := 0; i < qosRetries && err == ErrChannelFull; i++ {
+		select {
+		case <-h.done:
+			return
+		case <-time.After(interval):
+		}
+		// listeners are pushed under the lock only, see Checkpoint
+		e.mu.Lock()
+		sent, remove, err = lstnr.push(e, h.done, &event)
+		if sent {
+			lstnr.sent(e, event)
+		}
+		e.mu.Unlock()
+		interval *= 2
+	}
+
+	h.fail(err)
+	e.mu.Lock()
+	e.report(event, sent, err)
+	e.mu.Unlock()
+	notify(reports, lstnr, sent, remove, err)
+	if remove {
+		e.Off(event.Topic, lstnr.ch)
+	}
+}
+
+// eventID returns the ID of the event if the topic of the event
+// requires QoSExactlyOnce, it must be called under the lock.
+func (e *Emitter) eventID(event Event) (string, bool) {
+	if e.qos[event.Topic] != QoSExactlyOnce {
+		return "", false
+	}
+	v, _ := event.Meta("id")
+	id, ok := v.(string)
+	return id, ok
+}
+
+// newEventID returns a random event ID.
+func newEventID() string {
+	b := make([]byte, 16)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+// seenIDs keeps the IDs of the last events sent to a listener,
+// it must be used under the emitter lock.
+type seenIDs struct {
+	ids  map[string]struct{}
+	ring []string
+	next int
+}
+
+func (s *seenIDs) has(id string) bool {
+	_, ok := s.ids[id]
+	return ok
+}
+
+func (s *seenIDs) add(id string) {
+	if s.ids == nil {
+		s.ids = make(map[string]struct{})
+		s.ring = make([]string, qosDedupWindow)
+	}
+	if old := s.ring[s.next]; old != "" {
+		delete(s.ids, old)
+	}
+	s.ring[s.next] = id
+	s.ids[id] = struct{}{}
+	s.next = (s.next + 1) % len(s.ring)
+}
@@ -0,0 +1,52 @@
+package emitter
+
+import "testing"
+
+func TestQoSAtLeastOnce(t *testing.T) {
+	ee := New(1)
+	expect(t, ee.SetTopicQoS("test", QoSAtLeastOnce), nil)
+	expect(t, ee.SetTopicQoS("test", QoSLevel(10)), ErrInvalidQoS)
+	pipe := ee.On("test", Skip)
+
+	ee.Emit("test", 1).Wait()
+	// the channel is full, the first attempt fails
+	h := ee.Emit("test", 2)
+	expect(t, (<-pipe).Int(0), 1)
+	h.Wait()
+	expect(t, (<-pipe).Int(0), 2)
+
+	s := ee.Stats(false)
+	expect(t, s.TotalDeliveries, uint64(2))
+	expect(t, s.TotalDrops, uint64(0))
+}
+
+func TestQoSAtMostOnce(t *testing.T) {
+	ee := New(1)
+	expect(t, ee.SetTopicQoS("test", QoSAtLeastOnce), nil)
+	expect(t, ee.SetTopicQoS("test", QoSAtMostOnce), nil)
+	pipe := ee.On("test", Skip)
+
+	ee.Emit("test", 1).Wait()
+	ee.Emit("test", 2).Wait()
+	expect(t, (<-pipe).Int(0), 1)
+	expect(t, len(pipe), 0)
+}
+
+func TestQoSExactlyOnce(t *testing.T) {
+	ee := New(3)
+	expect(t, ee.SetTopicQoS("test", QoSExactlyOnce), nil)
+	pipe := ee.On("test")
+
+	// the events get different IDs
+	ee.Emit("test", 1).Wait()
+	ee.Emit("test", 2).Wait()
+	e1, e2 := <-pipe, <-pipe
+	id1, _ := e1.Meta("id")
+	id2, _ := e2.Meta("id")
+	expect(t, id1 != "" && id1 != id2, true)
+
+	// the event with a known ID is not sent again
+	ee.Use("test", func(e *Event) { e.SetMeta("id", id1) })
+	ee.Emit("test", 3).Wait()
+	expect(t, len(pipe), 0)
+}
@@ -0,0 +1,36 @@
+package emitter
+
+import "io"
+
+type redirect struct {
+	e    *Emitter
+	from string
+	ch   <-chan Event
+}
+
+// Redirect subscribes to the from topic, it can be pattern, and emits
+// received events to the to topic with the same arguments, metadata
+// and flags except FlagOnce. Middlewares of both topics are applied.
+// Events are emitted in order, the next event is emitted when the
+// previous one is delivered. The to topic must not be covered by the
+// from one. Close stops redirecting.
+func (e *Emitter) Redirect(from, to string) io.Closer {
+	ch := e.On(from)
+	go func() {
+		for event := range ch {
+			h, _ := e.publish(nil, emission{
+				topic: to,
+				args:  event.Args,
+				meta:  event.Metadata,
+				flags: event.Flags &^ FlagOnce,
+			})
+			h.Wait()
+		}
+	}()
+	return redirect{e: e, from: from, ch: ch}
+}
+
+func (r redirect) Close() error {
+	_, err := r.e.Off(r.from, r.ch)
+	return err
+}
@@ -0,0 +1,46 @@
+package emitter
+
+import (
+	"testing"
+	"time"
+)
+
+func TestRedirect(t *testing.T) {
+	ee := New(1)
+	ee.Use("to", Void)
+	pipe := ee.On("to")
+	r := ee.Redirect("from", "to")
+	noEvent := func(msg string) {
+		select {
+		case <-pipe:
+			t.Error(msg)
+		case <-time.After(10 * time.Millisecond):
+		}
+	}
+
+	ee.Emit("from", 1).Wait()
+	noEvent("the event is voided by the middleware of the to topic")
+
+	ee.Use("to")
+	ee.Use("from", Dedup)
+	ee.Emit("from", 2).Wait()
+	e := <-pipe
+	expect(t, e.Int(0), 2)
+	expect(t, e.OriginalTopic, "to")
+	expect(t, e.Flags, FlagDedup)
+
+	expect(t, r.Close(), nil)
+	ee.Emit("from", 3).Wait()
+	noEvent("the redirect is closed")
+	expect(t, ee.HasTopic("from"), false)
+
+	// FlagOnce is consumed by the redirect
+	ee.Use("from", Once)
+	ee.Redirect("from", "to")
+	ee.Emit("from", 4).Wait()
+	e = <-pipe
+	expect(t, e.Int(0), 4)
+	expect(t, e.Flags, FlagReset)
+	ee.Emit("from", 5).Wait()
+	noEvent("the redirect is removed by FlagOnce")
+}
@@ -0,0 +1,72 @@
+package emitter
+
+import "sync"
+
+// SafeEvent wraps an event to be changed by concurrent middlewares,
+// see Concurrent.
+type SafeEvent struct {
+	sync.RWMutex
+	event Event
+}
+
+// NewSafeEvent returns a safe wrapper of the event copy.
+func NewSafeEvent(e Event) *SafeEvent {
+	return &SafeEvent{event: e}
+}
+
+// Event returns a copy of the wrapped event.
+func (s *SafeEvent) Event() Event {
+	s.RLock()
+	defer s.RUnlock()
+	return s.event
+}
+
+// SetArg replaces the argument by index, see Event.SetArg.
+func (s *SafeEvent) SetArg(i int, v interface{}) error {
+	s.Lock()
+	defer s.Unlock()
+	return s.event.SetArg(i, v)
+}
+
+// GetArg returns the argument by index or nil if the index is out of range.
+func (s *SafeEvent) GetArg(i int) interface{} {
+	s.RLock()
+	defer s.RUnlock()
+	if i < 0 || i >= len(s.event.Args) {
+		return nil
+	}
+	return s.event.Args[i]
+}
+
+// SetMeta sets the metadata value by key, see Event.SetMeta.
+func (s *SafeEvent) SetMeta(k string, v interface{}) {
+	s.Lock()
+	defer s.Unlock()
+	s.event.SetMeta(k, v)
+}
+
+// GetMeta returns the metadata value by key.
+func (s *SafeEvent) GetMeta(k string) (interface{}, bool) {
+	s.RLock()
+	defer s.RUnlock()
+	return s.event.Meta(k)
+}
+
+// Concurrent returns a middleware which applies the functions to the
+// event concurrently and waits for them. The functions share the event
+// wrapped by SafeEvent, the changes are written back to the event.
+func Concurrent(fns ...func(*SafeEvent)) func(*Event) {
+	return func(e *Event) {
+		s := NewSafeEvent(*e)
+		var wg sync.WaitGroup
+		wg.Add(len(fns))
+		for _, fn := range fns {
+			go func(fn func(*SafeEvent)) {
+				defer wg.Done()
+				fn(s)
+			}(fn)
+		}
+		wg.Wait()
+		*e = s.Event()
+	}
+}
@@ -0,0 +1,41 @@
+package emitter
+
+import (
+	"strconv"
+	"testing"
+)
+
+func TestSafeEvent(t *testing.T) {
+	s := NewSafeEvent(Event{Args: []interface{}{1}})
+	expect(t, s.GetArg(0), 1)
+	expect(t, s.GetArg(1), nil)
+	expect(t, s.SetArg(1, 2), ErrArgIndex)
+	expect(t, s.SetArg(0, 2), nil)
+	expect(t, s.GetArg(0), 2)
+	_, ok := s.GetMeta("key")
+	expect(t, ok, false)
+	s.SetMeta("key", "value")
+	v, _ := s.GetMeta("key")
+	expect(t, v, "value")
+}
+
+func TestConcurrent(t *testing.T) {
+	var fns []func(*SafeEvent)
+	for i := 0; i < 10; i++ {
+		i := i
+		fns = append(fns, func(s *SafeEvent) {
+			s.SetMeta(strconv.Itoa(i), s.GetArg(i))
+			s.SetArg(i, i*2)
+		})
+	}
+
+	ee := New(1)
+	ee.Use("*", Concurrent(fns...))
+	pipe := ee.On("test")
+	ee.Emit("test", make([]interface{}, 10)...).Wait()
+	e := <-pipe
+	for i := uint(0); i < 10; i++ {
+		expect(t, e.Int(i), int(i)*2)
+	}
+	expect(t, len(e.Metadata), 10)
+}
@@ -0,0 +1,84 @@
+package emitter
+
+import (
+	"context"
+	"log/slog"
+)
+
+// slogHandler emits log records, see NewSlogEmitterHandler.
+type slogHandler struct {
+	e      *Emitter
+	attrs  []slog.Attr
+	groups []string
+}
+
+// NewSlogEmitterHandler returns a slog.Handler which emits log records
+// on "log.Debug", "log.Info", "log.Warn" and "log.Error" topics by level.
+// The first argument of an event is the message, the rest ones are
+// slog.Attr values. The level and the time of the record are attached
+// as "level" and "time" metadata.
+func NewSlogEmitterHandler(e *Emitter) slog.Handler {
+	return &slogHandler{e: e}
+}
+
+func (h *slogHandler) Enabled(context.Context, slog.Level) bool {
+	return true
+}
+
+func (h *slogHandler) Handle(_ context.Context, r slog.Record) error {
+	args := make([]interface{}, 0, 1+len(h.attrs)+r.NumAttrs())
+	args = append(args, r.Message)
+	for _, a := range h.attrs {
+		args = append(args, a)
+	}
+	r.Attrs(func(a slog.Attr) bool {
+		args = append(args, h.group(a))
+		return true
+	})
+
+	topic := "log." + slogLevel(r.Level)
+	h.e.publish(nil, emission{
+		topic: topic,
+		args:  args,
+		meta:  map[string]interface{}{"level": r.Level, "time": r.Time},
+	})
+	return nil
+}
+
+func (h *slogHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	clone := *h
+	clone.attrs = append([]slog.Attr{}, h.attrs...)
+	for _, a := range attrs {
+		clone.attrs = append(clone.attrs, h.group(a))
+	}
+	return &clone
+}
+
+func (h *slogHandler) WithGroup(name string) slog.Handler {
+	if name == "" {
+		return h
+	}
+	clone := *h
+	clone.groups = append(h.groups[:len(h.groups):len(h.groups)], name)
+	return &clone
+}
+
+// group nests the attribute into the groups of the handler.
+func (h *slogHandler) group(a slog.Attr) slog.Attr {
+	for i := len(h.groups) - 1; i >= 0; i-- {
+		a = slog.Group(h.groups[i], a)
+	}
+	return a
+}
+
+func slogLevel(level slog.Level) string {
+	switch {
+	case level < slog.LevelInfo:
+		return "Debug"
+	case level < slog.LevelWarn:
+		return "Info"
+	case level < slog.LevelError:
+		return "Warn"
+	}
+	return "Error"
+}
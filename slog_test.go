@@ -0,0 +1,29 @@
+package emitter
+
+import (
+	"log/slog"
+	"testing"
+)
+
+func TestSlogEmitterHandler(t *testing.T) {
+	ee := New(10)
+	info := ee.On("log.Info")
+	all := ee.On("log.*")
+	logger := slog.New(NewSlogEmitterHandler(ee))
+
+	logger.With("service", "api").WithGroup("req").Info("started", "id", 42)
+	e := <-info
+	expect(t, e.String(0), "started")
+	expect(t, len(e.Args), 3)
+	expect(t, e.Args[1].(slog.Attr).String(), "service=api")
+	expect(t, e.Args[2].(slog.Attr).String(), "req=[id=42]")
+	level, _ := e.Meta("level")
+	expect(t, level, slog.LevelInfo)
+	expect(t, (<-all).OriginalTopic, "log.Info")
+
+	logger.Error("failed")
+	e = <-all
+	expect(t, e.OriginalTopic, "log.Error")
+	expect(t, e.String(0), "failed")
+	expect(t, len(info), 0)
+}
@@ -0,0 +1,112 @@
+package emitter
+
+import (
+	"fmt"
+	"time"
+	"unsafe"
+)
+
+// Stats is a snapshot of the emitter metrics.
+type Stats struct {
+	// TotalEmits is the number of Emit calls.
+	TotalEmits uint64
+	// TotalDeliveries is the number of events sent to listeners.
+	TotalDeliveries uint64
+	// TotalDrops is the number of events which were not sent to
+	// a listener because its channel was blocked or closed.
+	TotalDrops    uint64
+	TopicCount    int
+	ListenerCount int
+	UptimeSecs    float64
+	// MemoryBytes is an estimation of the memory used by topics,
+	// listeners and middlewares. Event arguments are not counted.
+	MemoryBytes uint64
+}
+
+func (s Stats) String() string {
+	return fmt.Sprintf(
+		"emits: %d, deliveries: %d, drops: %d, topics: %d, listeners: %d, uptime: %.0fs, memory: %dB",
+		s.TotalEmits,
+		s.TotalDeliveries,
+		s.TotalDrops,
+		s.TopicCount,
+		s.ListenerCount,
+		s.UptimeSecs,
+		s.MemoryBytes,
+	)
+}
+
+// Stats returns a snapshot of the emitter metrics. Counters
+// are reset to zero after reading if reset is true.
+func (e *Emitter) Stats(reset bool) Stats {
+	e.mu.Lock()
+	e.init()
+	defer e.mu.Unlock()
+
+	s := Stats{
+		TopicCount: len(e.listeners),
+		UptimeSecs: time.Since(e.created).Seconds(),
+	}
+	if reset {
+		s.TotalEmits = e.emitted.Swap(0)
+		s.TotalDeliveries = e.delivered.Swap(0)
+		s.TotalDrops = e.dropped.Swap(0)
+	} else {
+		s.TotalEmits = e.emitted.Load()
+		s.TotalDeliveries = e.delivered.Load()
+		s.TotalDrops = e.dropped.Load()
+	}
+
+	const (
+		sliceSize    = unsafe.Sizeof([]listener{})
+		stringSize   = unsafe.Sizeof("")
+		listenerSize = unsafe.Sizeof(listener{})
+		eventSize    = unsafe.Sizeof(Event{})
+		funcSize     = unsafe.Sizeof(func(*Event) {})
+	)
+	var size uintptr
+	for topic, listeners := range e.listeners {
+		s.ListenerCount += len(listeners)
+		size += stringSize + uintptr(len(topic)) + sliceSize
+		size += uintptr(cap(listeners)) * listenerSize
+		for _, l := range listeners {
+			size += uintptr(cap(l.ch))*eventSize + uintptr(len(l.middlewares))*funcSize
+		}
+	}
+	for pattern, v := range e.middlewares {
+		size += stringSize + uintptr(len(pattern)) + sliceSize
+		size += uintptr(len(v.fns)) * funcSize
+	}
+	s.MemoryBytes = uint64(size)
+	return s
+}
+
+// ListenerStats describes a listener.
+type ListenerStats struct {
+	Topic    string
+	Len      int
+	Cap      int
+	Overflow OverflowStrategy
+}
+
+// ListenerStats returns description of the listener by its channel and
+// false if the channel is not subscribed.
+func (e *Emitter) ListenerStats(ch <-chan Event) (ListenerStats, bool) {
+	e.mu.Lock()
+	e.init()
+	defer e.mu.Unlock()
+
+	for topic, listeners := range e.listeners {
+		for _, l := range listeners {
+			if l.ch == ch {
+				return ListenerStats{
+					Topic:    topic,
+					Len:      len(l.ch),
+					Cap:      cap(l.ch),
+					Overflow: l.overflow,
+				}, true
+			}
+		}
+	}
+	return ListenerStats{}, false
+}
@@ -0,0 +1,36 @@
+package emitter
+
+import "testing"
+
+func TestStats(t *testing.T) {
+	ee := New(1)
+	ee.On("test1")
+	ee.On("test1")
+	ee.On("test2", Skip)
+
+	ee.Emit("test1").Wait()
+	ee.Emit("test2").Wait()
+	ee.Emit("test2").Wait() // dropped, the channel is full
+
+	s := ee.Stats(true)
+	expect(t, s.TotalEmits, uint64(3))
+	expect(t, s.TotalDeliveries, uint64(3))
+	expect(t, s.TotalDrops, uint64(1))
+	expect(t, s.ListenerCount, 3)
+	expect(t, s.TopicCount, 2)
+	expect(t, s.UptimeSecs > 0, true)
+	expect(t, s.MemoryBytes > 0, true)
+
+	ee.Emit("test2").Wait() // dropped
+	ee.Emit("unknown").Wait()
+
+	s = ee.Stats(false)
+	expect(t, s.TotalEmits, uint64(2))
+	expect(t, s.TotalDeliveries, uint64(0))
+	expect(t, s.TotalDrops, uint64(1))
+	expect(t, s.ListenerCount, 3)
+	expect(t, s.TopicCount, 2)
+
+	s = ee.Stats(false)
+	expect(t, s.TotalEmits, uint64(2))
+}
@@ -0,0 +1,104 @@
+package emitter
+
+import (
+	"encoding/json"
+	"io"
+	"sync"
+	"time"
+)
+
+// EventEncoder writes events to a stream, *json.Encoder
+// and *gob.Encoder satisfy this interface.
+type EventEncoder interface {
+	Encode(v interface{}) error
+}
+
+// EventDecoder reads events from a stream, *json.Decoder
+// and *gob.Decoder satisfy this interface.
+type EventDecoder interface {
+	Decode(v interface{}) error
+}
+
+// WriterEmitter writes emitted events into a stream, it
+// has no listeners. See ReaderEmitter for the other side.
+type WriterEmitter struct {
+	mu  sync.Mutex
+	enc EventEncoder
+}
+
+// NewWriterEmitter returns a WriterEmitter which writes events into w
+// using enc. JSON encoding is used if enc is nil.
+func NewWriterEmitter(w io.Writer, enc EventEncoder) *WriterEmitter {
+	if enc == nil {
+		enc = json.NewEncoder(w)
+	}
+	return &WriterEmitter{enc: enc}
+}
+
+// Emit writes an event with the rest arguments into the stream.
+func (w *WriterEmitter) Emit(topic string, args ...interface{}) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.enc.Encode(Event{
+		Topic:         topic,
+		OriginalTopic: topic,
+		Args:          args,
+		Timestamp:     time.Now(),
+	})
+}
+
+// ReaderEmitter is an emitter which emits events read from a stream,
+// the flags, the metadata and the timestamp of the read events are kept.
+type ReaderEmitter struct {
+	*Emitter
+
+	done chan struct{}
+	err  error
+}
+
+// NewReaderEmitter returns a ReaderEmitter which reads events from r
+// using dec until the stream ends. JSON decoding is used if dec is nil.
+func NewReaderEmitter(r io.Reader, dec EventDecoder) *ReaderEmitter {
+	if dec == nil {
+		dec = json.NewDecoder(r)
+	}
+	re := &ReaderEmitter{
+		Emitter: &Emitter{},
+		done:    make(chan struct{}),
+	}
+	go re.read(dec)
+	return re
+}
+
+func (re *ReaderEmitter) read(dec EventDecoder) {
+	defer close(re.done)
+	for {
+		var event Event
+		if err := dec.Decode(&event); err != nil {
+			if err != io.EOF {
+				re.err = err
+			}
+			return
+		}
+		h, _ := re.publish(nil, emission{
+			topic: event.Topic,
+			args:  event.Args,
+			meta:  event.Metadata,
+			flags: event.Flags,
+			time:  event.Timestamp,
+		})
+		h.Wait()
+	}
+}
+
+// Done returns a channel which is closed when the stream ends.
+func (re *ReaderEmitter) Done() <-chan struct{} {
+	return re.done
+}
+
+// Err waits until the stream ends and returns the error which
+// stopped reading, it is nil if the stream ended with io.EOF.
+func (re *ReaderEmitter) Err() error {
+	<-re.done
+	return re.err
+}
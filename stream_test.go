@@ -0,0 +1,57 @@
+package emitter
+
+import (
+	"encoding/json"
+	"io"
+	"testing"
+	"time"
+)
+
+func TestStreamEmitters(t *testing.T) {
+	r, w := io.Pipe()
+	re := NewReaderEmitter(r, nil)
+	pipe := re.On("test")
+	we := NewWriterEmitter(w, nil)
+
+	go func() {
+		expect(t, we.Emit("test", "value", 42), nil)
+		expect(t, we.Emit("test", true), nil)
+		w.Close()
+	}()
+
+	e := <-pipe
+	expect(t, e.Topic, "test")
+	expect(t, e.String(0), "value")
+	expect(t, e.Float(1), float64(42))
+	e = <-pipe
+	expect(t, e.Bool(0), true)
+	// the time of writing is kept
+	expect(t, time.Since(e.Timestamp) < time.Minute, true)
+
+	<-re.Done()
+	expect(t, re.Err(), nil)
+}
+
+func TestReaderEmitterKeepsEvent(t *testing.T) {
+	event, _ := json.Marshal(Event{
+		Topic:    "test",
+		Flags:    FlagSkip,
+		Args:     []interface{}{"value"},
+		Metadata: map[string]interface{}{"id": "1"},
+	})
+	r, w := io.Pipe()
+	re := NewReaderEmitter(r, nil)
+	pipe := re.On("test", WithCap(1))
+	go func() {
+		w.Write(event)
+		w.Close()
+	}()
+	<-re.Done()
+
+	// the event is skipped rather than blocking the stream
+	e := <-pipe
+	expect(t, e.String(0), "value")
+	expect(t, e.Flags&FlagSkip, FlagSkip)
+	id, _ := e.Meta("id")
+	expect(t, id, "1")
+}
@@ -0,0 +1,49 @@
+package emitter
+
+// OnTagged works exactly like On(see above) but labels the listener
+// with the tag, so it can be found or removed by the tag later.
+func (e *Emitter) OnTagged(tag string, topic string, middlewares ...func(*Event)) <-chan Event {
+	l := newListener(e.Cap, middlewares...)
+	l.tag = tag
+	return e.subscribe(topic, l)
+}
+
+// ListenersTagged returns listeners with the tag which were covered by
+// topic(it can be pattern) and error if pattern is invalid.
+func (e *Emitter) ListenersTagged(topic string, tag string) ([]<-chan Event, error) {
+	e.mu.Lock()
+	e.init()
+	defer e.mu.Unlock()
+	return e.tagged(topic, tag)
+}
+
+// OffTagged unsubscribes listeners with the tag which were covered by
+// topic(it can be pattern), it returns error if pattern is invalid.
+func (e *Emitter) OffTagged(topic string, tag string) error {
+	e.mu.Lock()
+	e.init()
+	channels, err := e.tagged(topic, tag)
+	e.mu.Unlock()
+	if err != nil || len(channels) == 0 {
+		return err
+	}
+	_, err = e.Off(topic, channels...)
+	return err
+}
+
+// tagged returns listeners with the tag, it must be called under the lock.
+func (e *Emitter) tagged(topic string, tag string) ([]<-chan Event, error) {
+	match, err := e.matched(topic)
+	if err != nil {
+		return nil, err
+	}
+	var acc []<-chan Event
+	for _, _topic := range match {
+		for _, l := range e.listeners[_topic] {
+			if l.tag == tag {
+				acc = append(acc, l.ch)
+			}
+		}
+	}
+	return acc, nil
+}
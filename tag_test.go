@@ -0,0 +1,42 @@
+package emitter
+
+import (
+	"path"
+	"testing"
+)
+
+func TestTaggedListeners(t *testing.T) {
+	ee := New(0)
+	a := ee.OnTagged("billing", "order.created")
+	b := ee.OnTagged("billing", "order.paid")
+	c := ee.OnTagged("mailer", "order.created")
+	d := ee.On("order.created")
+
+	l, err := ee.ListenersTagged("order.*", "billing")
+	expect(t, err, nil)
+	expect(t, len(l), 2)
+	l, err = ee.ListenersTagged("order.created", "mailer")
+	expect(t, err, nil)
+	expect(t, len(l), 1)
+	expect(t, l[0], c)
+	_, err = ee.ListenersTagged("[", "mailer")
+	expect(t, err, path.ErrBadPattern)
+
+	expect(t, ee.OffTagged("order.*", "billing"), nil)
+	_, ok := <-a
+	expect(t, ok, false)
+	_, ok = <-b
+	expect(t, ok, false)
+	expect(t, ee.HasTopic("order.paid"), false)
+	expect(t, len(ee.Listeners("order.created")), 2)
+
+	// nothing is removed if there are no tagged listeners
+	expect(t, ee.OffTagged("order.*", "billing"), nil)
+	expect(t, len(ee.Listeners("order.created")), 2)
+	expect(t, ee.OffTagged("[", "billing"), path.ErrBadPattern)
+
+	// untagged listeners have the empty tag
+	l, _ = ee.ListenersTagged("*", "")
+	expect(t, len(l), 1)
+	expect(t, l[0], d)
+}
@@ -0,0 +1,23 @@
+package emitter
+
+// TokenGate returns a middleware which sets FlagVoid flag for an event
+// if the "token" metadata is not a string accepted by verify.
+func TokenGate(verify func(token string) bool) func(*Event) {
+	return func(e *Event) {
+		token, _ := e.Meta("token")
+		if s, ok := token.(string); !ok || !verify(s) {
+			e.Flags = e.Flags | FlagVoid
+		}
+	}
+}
+
+// EmitWithToken works exactly like Emit but the token is attached
+// to the event as "token" metadata, see TokenGate.
+func (e *Emitter) EmitWithToken(token string, topic string, args ...interface{}) *EmitHandle {
+	h, _ := e.publish(nil, emission{
+		topic: topic,
+		args:  args,
+		meta:  map[string]interface{}{"token": token},
+	})
+	return h
+}
@@ -0,0 +1,21 @@
+package emitter
+
+import "testing"
+
+func TestTokenGate(t *testing.T) {
+	ee := New(10)
+	ee.Use("secure", TokenGate(func(token string) bool {
+		return token == "secret"
+	}))
+	pipe := ee.On("secure")
+
+	ee.EmitWithToken("secret", "secure", 1).Wait()
+	ee.EmitWithToken("wrong", "secure", 2).Wait()
+	ee.Emit("secure", 3).Wait()
+
+	expect(t, len(pipe), 1)
+	e := <-pipe
+	expect(t, e.Int(0), 1)
+	token, _ := e.Meta("token")
+	expect(t, token, "secret")
+}
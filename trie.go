@@ -0,0 +1,150 @@
+package emitter
+
+import (
+	"path"
+	"strings"
+)
+
+// topicIndex is a prefix tree of topics keyed on path segments. Topics
+// with character classes or escapes can match across segments, so they
+// are kept aside and always treated as candidates.
+type topicIndex struct {
+	root  *trieNode
+	other map[string]struct{}
+}
+
+type trieNode struct {
+	// literal and wild children keyed on the segment,
+	// wild segments contain * or ?
+	literal map[string]*trieNode
+	wild    map[string]*trieNode
+	topic   string
+	isTopic bool
+}
+
+func newTopicIndex() *topicIndex {
+	return &topicIndex{
+		root:  &trieNode{},
+		other: make(map[string]struct{}),
+	}
+}
+
+func isWildSegment(segment string) bool {
+	return strings.ContainsAny(segment, "*?")
+}
+
+func isIndexable(topic string) bool {
+	return !strings.ContainsAny(topic, `[\`)
+}
+
+func (idx *topicIndex) add(topic string) {
+	if !isIndexable(topic) {
+		idx.other[topic] = struct{}{}
+		return
+	}
+	node := idx.root
+	for _, segment := range strings.Split(topic, "/") {
+		children := &node.literal
+		if isWildSegment(segment) {
+			children = &node.wild
+		}
+		if *children == nil {
+			*children = make(map[string]*trieNode)
+		}
+		child, ok := (*children)[segment]
+		if !ok {
+			child = &trieNode{}
+			(*children)[segment] = child
+		}
+		node = child
+	}
+	node.topic, node.isTopic = topic, true
+}
+
+func (idx *topicIndex) remove(topic string) {
+	if !isIndexable(topic) {
+		delete(idx.other, topic)
+		return
+	}
+	idx.root.remove(strings.Split(topic, "/"))
+}
+
+// remove unsets the topic by the segments and
+// reports whether the node became empty.
+func (n *trieNode) remove(segments []string) bool {
+	if len(segments) == 0 {
+		n.topic, n.isTopic = "", false
+	} else {
+		children := n.literal
+		if isWildSegment(segments[0]) {
+			children = n.wild
+		}
+		if child, ok := children[segments[0]]; ok && child.remove(segments[1:]) {
+			delete(children, segments[0])
+		}
+	}
+	return !n.isTopic && len(n.literal) == 0 && len(n.wild) == 0
+}
+
+// candidates returns topics which can match the topic or be matched
+// by it, the result must be checked by the matcher.
+func (idx *topicIndex) candidates(topic string) []string {
+	var acc []string
+	for t := range idx.other {
+		acc = append(acc, t)
+	}
+	return idx.root.collect(strings.Split(topic, "/"), acc)
+}
+
+func (n *trieNode) collect(segments []string, acc []string) []string {
+	if len(segments) == 0 {
+		if n.isTopic {
+			acc = append(acc, n.topic)
+		}
+		return acc
+	}
+	segment, rest := segments[0], segments[1:]
+	if isWildSegment(segment) {
+		for _, child := range n.literal {
+			acc = child.collect(rest, acc)
+		}
+	} else if child, ok := n.literal[segment]; ok {
+		acc = child.collect(rest, acc)
+	}
+	for _, child := range n.wild {
+		acc = child.collect(rest, acc)
+	}
+	return acc
+}
+
+// matchedIndexed works like matched but checks only the index
+// candidates, it's applicable to the default matcher only.
+func (e *Emitter) matchedIndexed(topic string) ([]string, error) {
+	if len(e.listeners) == 0 {
+		return []string{}, nil
+	}
+	if _, err := path.Match(topic, ""); err != nil {
+		return []string{}, err
+	}
+	acc := []string{}
+	for _, k := range e.index.candidates(topic) {
+		if matched, _ := path.Match(topic, k); matched {
+			acc = append(acc, k)
+		} else if matched, _ := path.Match(k, topic); matched {
+			acc = append(acc, k)
+		}
+	}
+	return acc, nil
+}
+
+// reindex builds the index of the topics from scratch,
+// it must be called under the lock.
+func (e *Emitter) reindex() {
+	if e.index == nil {
+		return
+	}
+	e.index = newTopicIndex()
+	for topic := range e.listeners {
+		e.index.add(topic)
+	}
+}
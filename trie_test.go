@@ -0,0 +1,63 @@
+package emitter
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestTrieIndex(t *testing.T) {
+	topics := []string{
+		"", "a", "a/b", "a/c", "a/b/c", "a*", "a/*", "*/b", "a/?",
+		"a/b*/c", "*", "**", "a/[bc]", `a\/b`, "ab.cd", "ab.*",
+	}
+	queries := append([]string{"a/bc/c", "x/b", "ab.ef", "b", "a/[b]", "*/*/*", "["}, topics...)
+
+	plain := New(0)
+	indexed := New(0, WithTrieIndex())
+	for _, topic := range topics {
+		plain.On(topic)
+		indexed.On(topic)
+	}
+	check := func() {
+		for _, q := range queries {
+			expected, err := plain.TopicsMatching(q)
+			got, err2 := indexed.TopicsMatching(q)
+			sort.Strings(expected)
+			sort.Strings(got)
+			expect(t, strings.Join(got, ","), strings.Join(expected, ","))
+			expect(t, err2, err)
+		}
+	}
+	check()
+
+	for _, topic := range []string{"a/b", "a/*", `a\/b`, "**"} {
+		plain.Off(topic)
+		indexed.Off(topic)
+	}
+	check()
+
+	indexed.OffAll()
+	expect(t, len(indexed.index.candidates("*")), 0)
+	expect(t, len(indexed.index.root.literal)+len(indexed.index.root.wild), 0)
+}
+
+func BenchmarkTrieIndex(b *testing.B) {
+	for _, index := range []bool{false, true} {
+		b.Run("index="+strconv.FormatBool(index), func(b *testing.B) {
+			var options []Option
+			if index {
+				options = append(options, WithTrieIndex())
+			}
+			ee := New(0, options...)
+			for i := 0; i < 10000; i++ {
+				ee.On("service/"+strconv.Itoa(i%100)+"/topic/"+strconv.Itoa(i), Void)
+			}
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				ee.Emit("service/42/topic/4242").Wait()
+			}
+		})
+	}
+}
@@ -0,0 +1,139 @@
+package emitter
+
+import (
+	"path"
+	"time"
+)
+
+type topicTTL struct {
+	timer *time.Timer
+	// idle is true if the TTL is set by UseTopicTTL
+	idle bool
+}
+
+// SetTopicTTL schedules removal of the topic and all its listeners
+// after ttl. The previous TTL of the topic is replaced. It returns
+// ErrTopicNotFound if the topic doesn't exist.
+func (e *Emitter) SetTopicTTL(topic string, ttl time.Duration) error {
+	e.mu.Lock()
+	e.init()
+	defer e.mu.Unlock()
+
+	if _, ok := e.listeners[topic]; !ok {
+		return ErrTopicNotFound
+	}
+	e.setTTL(topic, ttl, false)
+	return nil
+}
+
+// RefreshTopicTTL schedules removal of the topic after ttl from now.
+// It returns ErrTopicNotFound if TTL is not set for the topic.
+func (e *Emitter) RefreshTopicTTL(topic string, ttl time.Duration) error {
+	e.mu.Lock()
+	_, ok := e.ttl[topic]
+	e.mu.Unlock()
+	if !ok {
+		return ErrTopicNotFound
+	}
+	return e.SetTopicTTL(topic, ttl)
+}
+
+// UseTopicTTL removes topics covered by the pattern with all their
+// listeners if nothing is emitted to them for idleTTL. The idle timer
+// of a topic is started when the topic is created and reset on each
+// Emit. TTL set by SetTopicTTL takes precedence over the idle one.
+func (e *Emitter) UseTopicTTL(pattern string, idleTTL time.Duration) error {
+	if !Test(pattern) {
+		return path.ErrBadPattern
+	}
+	e.mu.Lock()
+	e.init()
+	defer e.mu.Unlock()
+
+	if e.idleTTL == nil {
+		e.idleTTL = make(map[string]time.Duration)
+	}
+	e.idleTTL[pattern] = idleTTL
+	for topic := range e.listeners {
+		e.touch(topic)
+	}
+	return nil
+}
+
+// touch resets the idle timer of the topic if the topic is
+// covered by UseTopicTTL, it must be called under the lock.
+func (e *Emitter) touch(topic string) {
+	if len(e.idleTTL) == 0 || isMeta(topic) {
+		return
+	}
+	var ttl time.Duration
+	var found bool
+	for pattern, d := range e.idleTTL {
+		if matched, _ := e.match(pattern, topic); matched && (!found || d < ttl) {
+			ttl, found = d, true
+		}
+	}
+	if !found {
+		return
+	}
+	if entry, ok := e.ttl[topic]; ok {
+		if !entry.idle {
+			return
+		}
+		// the timer is fired already and the expiration
+		// is waiting for the lock, replace the entry then
+		if entry.timer.Stop() {
+			entry.timer.Reset(ttl)
+			return
+		}
+	}
+	e.setTTL(topic, ttl, true)
+}
+
+// setTTL schedules removal of the topic replacing the previous
+// one, it must be called under the lock.
+func (e *Emitter) setTTL(topic string, ttl time.Duration, idle bool) {
+	e.stopTTL(topic)
+	entry := &topicTTL{idle: idle}
+	entry.timer = time.AfterFunc(ttl, func() {
+		e.expire(topic, entry)
+	})
+	if e.ttl == nil {
+		e.ttl = make(map[string]*topicTTL)
+	}
+	e.ttl[topic] = entry
+}
+
+// stopTTL cancels the scheduled removal of the topic,
+// it must be called under the lock.
+func (e *Emitter) stopTTL(topic string) {
+	if entry, ok := e.ttl[topic]; ok {
+		entry.timer.Stop()
+		delete(e.ttl, topic)
+	}
+}
+
+// expire removes the topic if its TTL is not changed.
+func (e *Emitter) expire(topic string, entry *topicTTL) {
+	e.mu.Lock()
+	if e.ttl[topic] != entry {
+		e.mu.Unlock()
+		return
+	}
+	delete(e.ttl, topic)
+	for _, l := range e.listeners[topic] {
+		close(l.ch)
+	}
+	delete(e.listeners, topic)
+	if e.index != nil {
+		e.index.remove(topic)
+	}
+	if !isMeta(topic) {
+		e.queueMeta(TopicUnsubscribe, topic, 0)
+	}
+	e.mu.Unlock()
+
+	if !isMeta(topic) {
+		e.subscribersChanged(TopicUnsubscribe, topic, 0)
+	}
+}
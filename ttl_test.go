@@ -0,0 +1,70 @@
+package emitter
+
+import (
+	"path"
+	"testing"
+	"time"
+)
+
+func TestTopicTTL(t *testing.T) {
+	ee := New(1)
+	expect(t, ee.SetTopicTTL("test", 50*time.Millisecond), ErrTopicNotFound)
+	expect(t, ee.RefreshTopicTTL("test", 50*time.Millisecond), ErrTopicNotFound)
+
+	pipe := ee.On("test")
+	ee.On("other")
+	expect(t, ee.SetTopicTTL("test", 50*time.Millisecond), nil)
+	time.Sleep(60 * time.Millisecond)
+
+	ee.Emit("test").Wait()
+	_, ok := <-pipe
+	expect(t, ok, false)
+	expect(t, len(ee.Topics()), 1)
+	expect(t, ee.HasTopic("test"), false)
+}
+
+func TestRefreshTopicTTL(t *testing.T) {
+	ee := New(1)
+	ee.On("test")
+	expect(t, ee.SetTopicTTL("test", 50*time.Millisecond), nil)
+	time.Sleep(30 * time.Millisecond)
+	expect(t, ee.RefreshTopicTTL("test", 50*time.Millisecond), nil)
+	time.Sleep(30 * time.Millisecond)
+	expect(t, ee.HasTopic("test"), true)
+
+	// the topic is recreated after Off, the old TTL is not applied
+	ee.Off("test")
+	ee.On("test")
+	time.Sleep(30 * time.Millisecond)
+	expect(t, ee.HasTopic("test"), true)
+	expect(t, ee.RefreshTopicTTL("test", time.Millisecond), ErrTopicNotFound)
+}
+
+func TestUseTopicTTL(t *testing.T) {
+	ee := New(10)
+	expect(t, ee.UseTopicTTL("test[", time.Millisecond), path.ErrBadPattern)
+
+	ee.On("test1")
+	expect(t, ee.UseTopicTTL("test*", 50*time.Millisecond), nil)
+	ee.On("test2")
+	ee.On("other")
+
+	for i := 0; i < 4; i++ {
+		time.Sleep(20 * time.Millisecond)
+		ee.Emit("test1").Wait()
+	}
+	expect(t, ee.HasTopic("test1"), true)
+	expect(t, ee.HasTopic("test2"), false)
+	expect(t, ee.HasTopic("other"), true)
+
+	time.Sleep(60 * time.Millisecond)
+	expect(t, ee.HasTopic("test1"), false)
+	expect(t, ee.HasTopic("other"), true)
+
+	// explicit Off cancels the timer
+	ee.On("test3")
+	ee.Off("test3")
+	ee.mu.Lock()
+	expect(t, len(ee.ttl), 0)
+	ee.mu.Unlock()
+}
@@ -0,0 +1,127 @@
+package emitter
+
+import "sync"
+
+// TypedEmitter wraps an emitter to send and receive values of type T
+// as the first argument of events. Untyped listeners of the emitter
+// receive the events as well.
+type TypedEmitter[T any] struct {
+	// Mismatch is called for events which first argument is not of
+	// type T, such events are dropped.
+	Mismatch func(Event)
+
+	e        *Emitter
+	mu       sync.Mutex
+	channels map[<-chan T]typedListener
+}
+
+// typedListener is the listener which values are forwarded to the typed
+// channel, quit is closed to stop the forwarding on unsubscribing.
+type typedListener struct {
+	topic string
+	in    <-chan Event
+	quit  chan struct{}
+}
+
+// NewTypedEmitter returns a TypedEmitter which wraps the emitter.
+func NewTypedEmitter[T any](e *Emitter) *TypedEmitter[T] {
+	return &TypedEmitter[T]{
+		e:        e,
+		channels: make(map[<-chan T]typedListener),
+	}
+}
+
+// Emit emits the value as the only argument, see Emitter.Emit.
+func (t *TypedEmitter[T]) Emit(topic string, value T) *EmitHandle {
+	return t.e.Emit(topic, value)
+}
+
+// On returns a channel that will receive the first arguments of events,
+// see Emitter.On. The channel has the capacity of the emitter, it's closed
+// when the listener is unsubscribed even if the value is not received.
+func (t *TypedEmitter[T]) On(topic string, middlewares ...func(*Event)) <-chan T {
+	in := t.e.On(topic, middlewares...)
+	out := make(chan T, t.e.Cap)
+	quit := make(chan struct{})
+
+	t.mu.Lock()
+	t.channels[out] = typedListener{topic: topic, in: in, quit: quit}
+	t.mu.Unlock()
+
+	go func() {
+		defer func() {
+			t.mu.Lock()
+			delete(t.channels, out)
+			t.mu.Unlock()
+			close(out)
+		}()
+		for event := range in {
+			var value T
+			var ok bool
+			if len(event.Args) > 0 {
+				value, ok = event.Args[0].(T)
+			}
+			if !ok {
+				if t.Mismatch != nil {
+					t.Mismatch(event)
+				}
+				continue
+			}
+			select {
+			case out <- value:
+			case <-quit:
+				return
+			}
+		}
+	}()
+	return out
+}
+
+// Off unsubscribes the channels returned by On, or all listeners which
+// were covered by topic if there are no channels, see Emitter.Off.
+func (t *TypedEmitter[T]) Off(topic string, channels ...<-chan T) (int, error) {
+	if len(channels) == 0 {
+		n, err := t.e.Off(topic)
+		t.prune()
+		return n, err
+	}
+	t.mu.Lock()
+	acc := make([]<-chan Event, 0, len(channels))
+	for _, ch := range channels {
+		if l, ok := t.channels[ch]; ok {
+			acc = append(acc, l.in)
+			close(l.quit)
+			delete(t.channels, ch)
+		}
+	}
+	t.mu.Unlock()
+	if len(acc) == 0 {
+		return 0, nil
+	}
+	return t.e.Off(topic, acc...)
+}
+
+// prune stops forwarding to the channels which
+// listeners are not subscribed anymore.
+func (t *TypedEmitter[T]) prune() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	for ch, l := range t.channels {
+		var subscribed bool
+		for _, in := range t.e.Listeners(l.topic) {
+			if in == l.in {
+				subscribed = true
+				break
+			}
+		}
+		if !subscribed {
+			close(l.quit)
+			delete(t.channels, ch)
+		}
+	}
+}
+
+// Use registers middlewares for the pattern, see Emitter.Use.
+func (t *TypedEmitter[T]) Use(pattern string, middlewares ...func(*Event)) {
+	t.e.Use(pattern, middlewares...)
+}
@@ -0,0 +1,60 @@
+package emitter
+
+import "testing"
+
+type user struct {
+	Name string
+}
+
+func TestTypedEmitter(t *testing.T) {
+	ee := New(10)
+	te := NewTypedEmitter[user](ee)
+	var mismatched []Event
+	te.Mismatch = func(e Event) {
+		mismatched = append(mismatched, e)
+	}
+
+	pipe := te.On("user")
+	untyped := ee.On("user")
+
+	te.Emit("user", user{Name: "alice"}).Wait()
+	ee.Emit("user", "bob").Wait()
+	te.Emit("user", user{Name: "carol"}).Wait()
+
+	expect(t, (<-pipe).Name, "alice")
+	expect(t, (<-pipe).Name, "carol")
+	expect(t, len(untyped), 3)
+	expect(t, len(mismatched), 1)
+	expect(t, mismatched[0].String(0), "bob")
+
+	removed, err := te.Off("user", pipe)
+	expect(t, err, nil)
+	expect(t, removed, 1)
+	_, ok := <-pipe
+	expect(t, ok, false)
+	expect(t, len(ee.Listeners("user")), 1)
+}
+
+func TestTypedEmitterOff(t *testing.T) {
+	ee := New(0)
+	te := NewTypedEmitter[int](ee)
+
+	// the values are not received
+	pipe := te.On("test")
+	other := te.On("other")
+	te.Emit("test", 1).Wait()
+	te.Emit("other", 2).Wait()
+
+	removed, err := te.Off("test", pipe)
+	expect(t, err, nil)
+	expect(t, removed, 1)
+	for range pipe {
+	}
+
+	removed, err = te.Off("*")
+	expect(t, err, nil)
+	expect(t, removed, 1)
+	for range other {
+	}
+	expect(t, len(te.channels), 0)
+}